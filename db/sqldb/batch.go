@@ -0,0 +1,33 @@
+package sqldb
+
+import "strings"
+
+// Batch queues multiple queries to be sent in a single round trip.
+// Results are read in queue order from the returned Rows, advancing with Rows.NextResultSet()
+type Batch struct {
+	Queries []BatchQuery
+}
+
+type BatchQuery struct {
+	SQL  string
+	Args []any
+}
+
+// Queue appends a query to the batch. Returns the batch for chaining
+func (b *Batch) Queue(query string, args ...any) *Batch {
+	b.Queries = append(b.Queries, BatchQuery{SQL: query, Args: args})
+	return b
+}
+
+func (b *Batch) Len() int {
+	return len(b.Queries)
+}
+
+// SQL returns the queued queries joined, for logging and instrumentation
+func (b *Batch) SQL() string {
+	queries := make([]string, len(b.Queries))
+	for i, q := range b.Queries {
+		queries[i] = q.SQL
+	}
+	return strings.Join(queries, ";\n")
+}
@@ -9,5 +9,5 @@ import (
 type Client interface {
 	db.Client[DBHandle]
 
-	BeginTx(ctx context.Context) (Tx, error)
+	BeginTx(ctx context.Context, opts *TxOptions) (Tx, error)
 }
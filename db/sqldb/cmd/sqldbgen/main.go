@@ -0,0 +1,212 @@
+// Command sqldbgen generates typed accessors for the raw stmts of a group,
+// so a typo in a stmt key or a wrong number of args fails at compile time.
+//
+// Usage, next to the embedded `sql` dir registered with sqldb.RegisterGroup:
+//
+//	//go:generate go run github.com/LearnLoop365/flxr-core/db/sqldb/cmd/sqldbgen -group user
+//
+// For each stmt (sql/users/find.sql -> key "user.users.find") it emits
+//   - a key constant: StmtKeyUsersFind = "user.users.find"
+//   - an accessor taking exactly the stmt's args:
+//     StmtUsersFind(store *sqldb.RawStore, id, name any) (string, []any, error)
+//
+// Named parameters become accessor params; `?` placeholders become arg1..argN;
+// stmts with dynamic placeholders take variadic args.
+// Dialect files (.pgsql, .mysql, .sqlite) must agree with the standard SQL file on the number of args
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+type stmt struct {
+	key      string
+	name     string // Go identifier suffix
+	files    []string
+	params   []string // named parameters, nil if positional
+	arity    int
+	variadic bool
+}
+
+func main() {
+	group := flag.String("group", "", "stmt group name as registered with sqldb.RegisterGroup (required)")
+	dir := flag.String("dir", ".", "dir containing the `sql` dir")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package name of the generated file (default $GOPACKAGE)")
+	out := flag.String("out", "sqlstmts_gen.go", "output file, relative to dir")
+	flag.Parse()
+	if *group == "" || *pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	stmts, err := readStmts(os.DirFS(*dir), *group)
+	if err != nil {
+		log.Fatalf("sqldbgen: %v", err)
+	}
+	src, err := generate(*pkg, *group, stmts)
+	if err != nil {
+		log.Fatalf("sqldbgen: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(*dir, *out), src, 0o644); err != nil {
+		log.Fatalf("sqldbgen: %v", err)
+	}
+}
+
+// readStmts reads the `sql` dir like sqldb.RawStoreRegistry and derives the args of each stmt
+func readStmts(fsys fs.FS, group string) ([]*stmt, error) {
+	byKey := make(map[string]*stmt)
+	err := fs.WalkDir(fsys, "sql", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := path.Ext(p)
+		dialect := strings.TrimPrefix(ext, ".")
+		if _, known := sqldb.PlaceholderPrefixForDBType[dialect]; !known && dialect != "sql" {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		name := strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(p, "sql/"), ext), "/", ".")
+		key := sqldb.StoreGroupedStmtKey{Group: group, StmtName: name}.String()
+		s, exists := byKey[key]
+		if !exists {
+			s = &stmt{key: key, name: goName(name), arity: -1}
+			byKey[key] = s
+		}
+		s.files = append(s.files, p)
+		return s.addFile(p, dialect, string(data))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := slices.SortedFunc(maps.Values(byKey), func(a, b *stmt) int { return strings.Compare(a.key, b.key) })
+	names := make(map[string]string)
+	for _, s := range stmts {
+		if other, exists := names[s.name]; exists {
+			return nil, fmt.Errorf("stmts %q and %q both map to Stmt%s", other, s.key, s.name)
+		}
+		names[s.name] = s.key
+	}
+	return stmts, nil
+}
+
+func (s *stmt) addFile(p, dialect, data string) error {
+	var arity int
+	variadic := sqldb.HasDynamicPlaceholders(data)
+	switch dialect {
+	case "sql":
+		// unique names in order of first appearance, as sqldb.RawStore.Bind takes them
+		_, params, err := sqldb.CompileNamedPlaceholders(data, '$')
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		if params != nil {
+			s.params = params
+			arity = len(params)
+		} else {
+			arity = sqldb.StaticPlaceholderCount(data, '$')
+		}
+	default:
+		switch prefix := sqldb.PlaceholderPrefixForDBType[dialect]; prefix {
+		case '?', 0:
+			arity = sqldb.StaticPlaceholderCount(data, prefix)
+		default:
+			arity = sqldb.MaxNumberedPlaceholder(data, prefix)
+		}
+	}
+
+	if s.arity >= 0 && !variadic && !s.variadic && s.arity != arity {
+		return fmt.Errorf("%s: %d args, but %s has %d", p, arity, strings.Join(s.files[:len(s.files)-1], ", "), s.arity)
+	}
+	s.arity = arity
+	s.variadic = s.variadic || variadic
+	return nil
+}
+
+func generate(pkg, group string, stmts []*stmt) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by sqldbgen; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&b, "import %q\n\n", "github.com/LearnLoop365/flxr-core/db/sqldb")
+
+	fmt.Fprintf(&b, "// Raw stmt keys of group %q\nconst (\n", group)
+	for _, s := range stmts {
+		fmt.Fprintf(&b, "StmtKey%s = %q\n", s.name, s.key)
+	}
+	b.WriteString(")\n")
+
+	for _, s := range stmts {
+		fmt.Fprintf(&b, "\n// Stmt%s returns the %q stmt (%s)", s.name, s.key, strings.Join(s.files, ", "))
+		switch {
+		case s.variadic:
+			b.WriteString(" with its args; it has dynamic placeholders\n")
+			fmt.Fprintf(&b, "func Stmt%s(store *sqldb.RawStore, args ...any) (string, []any, error) {\n", s.name)
+			fmt.Fprintf(&b, "return store.GetWithArgs(StmtKey%s, args...)\n}\n", s.name)
+		case s.params != nil:
+			fmt.Fprintf(&b, " bound to its %d named args\n", s.arity)
+			idents := make([]string, len(s.params))
+			entries := make([]string, len(s.params))
+			for i, param := range s.params {
+				idents[i] = paramIdent(param)
+				entries[i] = fmt.Sprintf("%q: %s", param, idents[i])
+			}
+			fmt.Fprintf(&b, "func Stmt%s(store *sqldb.RawStore, %s any) (string, []any, error) {\n", s.name, strings.Join(idents, ", "))
+			fmt.Fprintf(&b, "return store.Bind(StmtKey%s, map[string]any{%s})\n}\n", s.name, strings.Join(entries, ", "))
+		default:
+			fmt.Fprintf(&b, " with its %d args\n", s.arity)
+			idents := make([]string, s.arity)
+			for i := range idents {
+				idents[i] = fmt.Sprintf("arg%d", i+1)
+			}
+			params := ""
+			if s.arity > 0 {
+				params = ", " + strings.Join(idents, ", ") + " any"
+			}
+			fmt.Fprintf(&b, "func Stmt%s(store *sqldb.RawStore%s) (string, []any, error) {\n", s.name, params)
+			fmt.Fprintf(&b, "return store.GetWithArgs(StmtKey%s%s)\n}\n", s.name, prefixed(", ", idents))
+		}
+	}
+	return format.Source(b.Bytes())
+}
+
+func prefixed(sep string, idents []string) string {
+	if len(idents) == 0 {
+		return ""
+	}
+	return sep + strings.Join(idents, sep)
+}
+
+// goName converts a stmt name (users.find_by_email) into an exported identifier suffix (UsersFindByEmail)
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '_' || r == '-' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// paramIdent converts a parameter name (user_id) into an unexported identifier (userId)
+func paramIdent(param string) string {
+	name := goName(param)
+	ident := strings.ToLower(name[:1]) + name[1:]
+	if token.IsKeyword(ident) || ident == "store" {
+		ident += "Arg"
+	}
+	return ident
+}
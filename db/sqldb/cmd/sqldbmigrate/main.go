@@ -0,0 +1,79 @@
+// Command sqldbmigrate runs schema migrations from a dir on disk.
+//
+//	sqldbmigrate -conf db.json -dir migrations up
+//	sqldbmigrate -conf db.json -dir migrations down 2
+//	sqldbmigrate -conf db.json -dir migrations status
+//
+// The conf file is a JSON sqldb.Conf; its type selects the impl (pgsql, mysql, sqlite).
+// Apps embedding their migrations can call migrations.Run with their own client and fs.FS instead
+package main
+
+import (
+	"context"
+	"encoding/json/v2"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/LearnLoop365/flxr-core/db"
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"github.com/LearnLoop365/flxr-core/db/sqldb/impls/mysql"
+	"github.com/LearnLoop365/flxr-core/db/sqldb/impls/pgsql"
+	"github.com/LearnLoop365/flxr-core/db/sqldb/impls/sqlite"
+	"github.com/LearnLoop365/flxr-core/db/sqldb/migrations"
+)
+
+func main() {
+	confPath := flag.String("conf", "", "JSON sqldb.Conf file (required)")
+	dir := flag.String("dir", "migrations", "migrations dir")
+	table := flag.String("table", migrations.DefaultTable, "table recording applied migrations")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "sqldbmigrate [flags] <command>\n%s\nflags:\n", migrations.Usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *confPath == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*confPath)
+	if err != nil {
+		log.Fatalf("sqldbmigrate: %v", err)
+	}
+	var conf sqldb.Conf
+	if err = json.Unmarshal(data, &conf); err != nil {
+		log.Fatalf("sqldbmigrate: invalid conf. %v", err)
+	}
+
+	var client sqldb.Client
+	switch conf.Type {
+	case "pgsql":
+		client = &pgsql.Client{Conf: &conf}
+	case "mysql":
+		client = &mysql.Client{Conf: &conf}
+	case "sqlite":
+		client = &sqlite.Client{Conf: &conf}
+	default:
+		log.Fatalf("sqldbmigrate: unsupported conf type %q", conf.Type)
+	}
+	if err = client.Init(); err != nil {
+		log.Fatalf("sqldbmigrate: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	m := &migrations.Migrator{
+		Client: client,
+		DBType: conf.Type,
+		FS:     os.DirFS(*dir),
+		Table:  *table,
+	}
+	err = migrations.Run(ctx, m, flag.Args(), os.Stdout)
+	stop()
+	db.CloseClient("sqldbmigrate", client)
+	if err != nil {
+		log.Fatalf("sqldbmigrate: %v", err)
+	}
+}
@@ -1,5 +1,14 @@
 package sqldb
 
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json/v2"
+	"fmt"
+	"os"
+	"time"
+)
+
 type Conf struct {
 	Type   string `json:"type"` // mysql, pgsql, mssql, oracle, maria, sqlite, ...
 	Host   string `json:"host"`
@@ -9,4 +18,135 @@ type Conf struct {
 	PW     string `json:"pw"`
 	DB     string `json:"db"`
 	TZ     string `json:"tz"` // Connection Timezone
+
+	Pool           PoolConf          `json:"pool"`
+	ConnectTimeout Duration          `json:"connect_timeout"` // default: 5s
+	TLS            TLSConf           `json:"tls"`
+	AppName        string            `json:"app_name"` // reported to the server (pgsql application_name, mysql program_name)
+	Params         map[string]string `json:"params"`   // extra driver DSN params, passed as-is
+
+	// Read replicas (see ReplicatedClient). Unset fields are inherited from this conf, TLS.ServerName only for the same Host
+	Replicas      []Conf `json:"replicas"`
+	ReplicaPolicy string `json:"replica_policy"` // round_robin (default), least_latency
+}
+
+// PoolConf - zero values keep the impl defaults
+type PoolConf struct {
+	MaxConns        int      `json:"max_conns"`          // default: 10
+	MinConns        int      `json:"min_conns"`          // pgsql only. default: 2
+	MaxIdleConns    int      `json:"max_idle_conns"`     // mysql, sqlite. default: max_conns
+	MaxConnLifetime Duration `json:"max_conn_lifetime"`  // default: 3m
+	MaxConnIdleTime Duration `json:"max_conn_idle_time"` // default: driver default
+	StmtCacheSize   int      `json:"stmt_cache_size"`    // pgsql only. prepared stmts kept per connection. default: 256
+}
+
+const (
+	TLSModeDisable    = "disable"     // plain connection (default)
+	TLSModeRequire    = "require"     // encrypted, server cert not verified
+	TLSModeVerifyCA   = "verify-ca"   // server cert signed by a trusted CA
+	TLSModeVerifyFull = "verify-full" // verify-ca + server host name matches the cert
+)
+
+type TLSConf struct {
+	Mode       string `json:"mode"` // disable, require, verify-ca, verify-full (PostgreSQL sslmode names)
+	CAFile     string `json:"ca_file"`
+	CertFile   string `json:"cert_file"` // client cert for mutual TLS
+	KeyFile    string `json:"key_file"`
+	ServerName string `json:"server_name"` // default: Conf.Host
+}
+
+// Config builds a *tls.Config for drivers that take one directly. nil for disable mode
+func (t TLSConf) Config(host string) (*tls.Config, error) {
+	switch t.Mode {
+	case "", TLSModeDisable:
+		return nil, nil
+	case TLSModeRequire, TLSModeVerifyCA, TLSModeVerifyFull:
+	default:
+		return nil, fmt.Errorf("unknown tls mode %q", t.Mode)
+	}
+
+	serverName := t.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+	cfg := &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls ca file: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in tls ca file %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls client cert: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	switch t.Mode {
+	case TLSModeRequire:
+		cfg.InsecureSkipVerify = true
+	case TLSModeVerifyCA:
+		// verify the chain only; the standard verification would also check the host name
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("server presented no certificate")
+			}
+			opts := x509.VerifyOptions{Roots: cfg.RootCAs, Intermediates: x509.NewCertPool()}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		}
+	}
+	return cfg, nil
+}
+
+// Duration is a time.Duration read from JSON as a string like "30s", "3m" (or nanoseconds as a number)
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch val := v.(type) {
+	case nil:
+		*d = 0
+	case float64:
+		*d = Duration(val)
+	case string:
+		if val == "" {
+			*d = 0
+			return nil
+		}
+		parsed, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration: %s", data)
+	}
+	return nil
+}
+
+// Or returns d, or def if d is not set
+func (d Duration) Or(def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return time.Duration(d)
 }
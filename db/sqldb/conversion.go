@@ -1,25 +1,299 @@
 package sqldb
 
 import (
+	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 )
 
+// ConvertStaticPlaceholders converts `?` placeholders into the positional form of prefix ($1, @1, :1, ...).
+// It skips string literals ('...', E'...', $tag$...$tag$), quoted identifiers ("..." and `...`)
+// and comments (--, /* */); for mysql ('?') literals take backslash escapes (`'it\'s'`). In code:
+//   - `??` is an escaped literal `?` (e.g. the PostgreSQL JSONB `?` operator: `data ?? 'key'`)
+//   - for pgsql ('$'), `?|` and `?&` are kept as JSONB operators (`?||` is still a placeholder followed by `||`)
 func ConvertStaticPlaceholders(sql string, prefix byte) string {
-	if prefix == '?' || prefix == 0 {
+	if !strings.Contains(sql, "?") {
 		return sql
 	}
+	return ReplaceStaticPlaceholders(sql, prefix, func(n int) string {
+		if prefix == '?' || prefix == 0 {
+			return "?"
+		}
+		return string(prefix) + strconv.Itoa(n+1)
+	})
+}
+
+// ReplaceStaticPlaceholders writes replace(n) in place of the n-th (0-based) `?` placeholder of sql,
+// which is scanned like in ConvertStaticPlaceholders
+func ReplaceStaticPlaceholders(sql string, prefix byte, replace func(n int) string) string {
 	var builder strings.Builder
 	builder.Grow(len(sql) + 8) // small padding; rough pre-optimization
-	cnt := 1
-	for i := 0; i < len(sql); i++ {
-		if sql[i] == '?' {
-			builder.WriteByte(prefix)
-			builder.WriteString(strconv.Itoa(cnt))
-			cnt++
-		} else {
+	cnt := 0
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, prefix); j > i {
+			builder.WriteString(sql[i:j])
+			i = j
+			continue
+		}
+		if sql[i] != '?' {
 			builder.WriteByte(sql[i])
+			i++
+			continue
+		}
+		switch {
+		case isEscapedQuestionMark(sql, i):
+			builder.WriteByte('?')
+			i += 2
+		case isJSONBOperator(sql, i, prefix):
+			builder.WriteString(sql[i : i+2])
+			i += 2
+		default:
+			builder.WriteString(replace(cnt))
+			cnt++
+			i++
 		}
 	}
 	return builder.String()
 }
+
+func isEscapedQuestionMark(sql string, i int) bool {
+	return i+1 < len(sql) && sql[i+1] == '?'
+}
+
+// isJSONBOperator reports whether sql[i] starts `?|` or `?&` (not `?||` or `?&&`).
+// Only pgsql ('$') has them; elsewhere `?|4` is a placeholder followed by `|4`
+func isJSONBOperator(sql string, i int, prefix byte) bool {
+	if prefix != '$' || i+1 >= len(sql) || sql[i+1] != '|' && sql[i+1] != '&' {
+		return false
+	}
+	return i+2 >= len(sql) || sql[i+2] != sql[i+1]
+}
+
+// skipNonCode returns the index just past the string literal, quoted identifier or comment starting at sql[i],
+// or i if none starts there. Unterminated ones run to the end of sql.
+// prefix is the placeholder prefix of the dialect (PlaceholderPrefixForDBType); 0 for standard SQL
+func skipNonCode(sql string, i int, prefix byte) int {
+	switch c := sql[i]; c {
+	case '\'':
+		// mysql strings and E'...' (PostgreSQL escape string) allow backslash escapes
+		escapes := prefix == '?' ||
+			i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i < 2 || !isIdentByte(sql[i-2]))
+		return skipQuoted(sql, i, '\'', escapes)
+	case '"':
+		// "..." is a string in mysql (without ANSI_QUOTES), an identifier elsewhere
+		return skipQuoted(sql, i, c, prefix == '?')
+	case '`':
+		return skipQuoted(sql, i, c, false)
+	case '-':
+		if i+1 < len(sql) && sql[i+1] == '-' {
+			if j := strings.IndexByte(sql[i:], '\n'); j >= 0 {
+				return i + j + 1
+			}
+			return len(sql)
+		}
+	case '/':
+		if i+1 < len(sql) && sql[i+1] == '*' {
+			return skipBlockComment(sql, i)
+		}
+	case '$':
+		return skipDollarQuoted(sql, i)
+	}
+	return i
+}
+
+// skipQuoted skips a quoted token where a doubled quote is an escaped quote
+func skipQuoted(sql string, i int, quote byte, backslashEscapes bool) int {
+	for j := i + 1; j < len(sql); j++ {
+		switch sql[j] {
+		case '\\':
+			if backslashEscapes {
+				j++
+			}
+		case quote:
+			if j+1 < len(sql) && sql[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(sql)
+}
+
+// skipBlockComment skips /* ... */, nested as in PostgreSQL
+func skipBlockComment(sql string, i int) int {
+	depth := 0
+	for j := i; j+1 < len(sql); j++ {
+		switch {
+		case sql[j] == '/' && sql[j+1] == '*':
+			depth++
+			j++
+		case sql[j] == '*' && sql[j+1] == '/':
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(sql)
+}
+
+// skipDollarQuoted skips $$...$$ or $tag$...$tag$. Positional params like $1 are not tags
+func skipDollarQuoted(sql string, i int) int {
+	if i > 0 && isIdentByte(sql[i-1]) {
+		return i
+	}
+	j := i + 1
+	for j < len(sql) && isIdentByte(sql[j]) {
+		j++
+	}
+	if j >= len(sql) || sql[j] != '$' || j > i+1 && !isIdentStart(sql[i+1]) {
+		return i
+	}
+	tag := sql[i : j+1]
+	if k := strings.Index(sql[j+1:], tag); k >= 0 {
+		return j + 1 + k + len(tag)
+	}
+	return len(sql)
+}
+
+// HasDynamicPlaceholders reports whether sql has a dynamic placeholder: a bare `@` in code
+// (not `@name`, `@1` or MySQL `@@var`)
+func HasDynamicPlaceholders(sql string) bool {
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, 0); j > i {
+			i = j
+			continue
+		}
+		if sql[i] == '@' && (i == 0 || sql[i-1] != '@') &&
+			(i+1 >= len(sql) || !isIdentByte(sql[i+1]) && sql[i+1] != '@') {
+			return true
+		}
+		i++
+	}
+	return false
+}
+
+// StaticPlaceholderCount returns the number of `?` placeholders in code, like ConvertStaticPlaceholders counts them
+func StaticPlaceholderCount(sql string, prefix byte) int {
+	cnt := 0
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, prefix); j > i {
+			i = j
+			continue
+		}
+		switch {
+		case sql[i] != '?':
+			i++
+		case isEscapedQuestionMark(sql, i), isJSONBOperator(sql, i, prefix):
+			i += 2
+		default:
+			cnt++
+			i++
+		}
+	}
+	return cnt
+}
+
+// MaxNumberedPlaceholder returns the largest n of the numbered placeholders (e.g. `$n`) in code
+func MaxNumberedPlaceholder(sql string, prefix byte) int {
+	maxN := 0
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, prefix); j > i {
+			i = j
+			continue
+		}
+		if sql[i] != prefix || i > 0 && isIdentByte(sql[i-1]) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(sql) && '0' <= sql[j] && sql[j] <= '9' {
+			j++
+		}
+		if n, err := strconv.Atoi(sql[i+1 : j]); err == nil {
+			maxN = max(maxN, n)
+		}
+		i = j
+	}
+	return maxN
+}
+
+// HasReturningClause reports whether sql has the RETURNING keyword in code,
+// not in string literals, quoted identifiers or comments
+func HasReturningClause(sql string) bool {
+	return slices.Contains(codeKeywords(sql, 0), "RETURNING")
+}
+
+// TrimStatementEnd returns sql without the trailing whitespace, `;` and comments,
+// so a clause can be appended to it. Fails if sql has more than one statement.
+// prefix is the placeholder prefix of the dialect, as in ConvertStaticPlaceholders
+func TrimStatementEnd(sql string, prefix byte) (string, error) {
+	end := 0
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, prefix); j > i {
+			if !strings.HasPrefix(sql[i:], "--") && !strings.HasPrefix(sql[i:], "/*") {
+				end = j // literal or quoted identifier
+			}
+			i = j
+			continue
+		}
+		switch c := sql[i]; {
+		case c == ';':
+			if hasCode(sql[i+1:], prefix) {
+				return "", fmt.Errorf("expected a single statement")
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+		default:
+			end = i + 1
+		}
+		i++
+	}
+	return sql[:end], nil
+}
+
+func isSpaceOrSemicolon(c byte) bool {
+	return c == ';' || c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// hasCode reports whether sql has anything but whitespace, `;` and comments
+func hasCode(sql string, prefix byte) bool {
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, prefix); j > i {
+			if !strings.HasPrefix(sql[i:], "--") && !strings.HasPrefix(sql[i:], "/*") {
+				return true
+			}
+			i = j
+			continue
+		}
+		if !isSpaceOrSemicolon(sql[i]) {
+			return true
+		}
+		i++
+	}
+	return false
+}
+
+// codeKeywords returns the upper-cased words in code, not in literals, quoted identifiers or comments
+func codeKeywords(sql string, prefix byte) []string {
+	var words []string
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, prefix); j > i {
+			i = j
+			continue
+		}
+		if !isIdentStart(sql[i]) || i > 0 && (isIdentByte(sql[i-1]) || sql[i-1] == '.' || sql[i-1] == '@' || sql[i-1] == '$') {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(sql) && isIdentByte(sql[j]) {
+			j++
+		}
+		words = append(words, strings.ToUpper(sql[i:j]))
+		i = j
+	}
+	return words
+}
@@ -0,0 +1,132 @@
+package sqldb
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestConvertStaticPlaceholders(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		prefix byte
+		want   string
+	}{
+		{"plain", "SELECT * FROM t WHERE a = ? AND b = ?", '$', "SELECT * FROM t WHERE a = $1 AND b = $2"},
+		{"no placeholders", "SELECT 1", '$', "SELECT 1"},
+		{"mssql", "SELECT ? , ?", '@', "SELECT @1 , @2"},
+		{"oracle", "SELECT ?", ':', "SELECT :1"},
+		{"mysql unchanged", "SELECT ? FROM t WHERE a = ?", '?', "SELECT ? FROM t WHERE a = ?"},
+		{"sqlite unchanged", "SELECT ? FROM t WHERE a = ?", 0, "SELECT ? FROM t WHERE a = ?"},
+
+		{"literal", "SELECT '?' , ?", '$', "SELECT '?' , $1"},
+		{"literal doubled quote", "SELECT 'it''s ?' , ?", '$', "SELECT 'it''s ?' , $1"},
+		{"escape string", `SELECT E'\'?' , ?`, '$', `SELECT E'\'?' , $1`},
+		{"escape string lower", `SELECT e'\\' , ?`, '$', `SELECT e'\\' , $1`},
+		{"identifier ending in E", `SELECT name'?' , ?`, '$', `SELECT name'?' , $1`},
+		{"standard literal backslash", `SELECT '\' , ?`, '$', `SELECT '\' , $1`},
+		{"mysql backslash escape", `SELECT 'it\'s ?' , ?`, '?', `SELECT 'it\'s ?' , ?`},
+		{"mysql double-quoted string", `SELECT "a\"?" , ?`, '?', `SELECT "a\"?" , ?`},
+		{"dollar quoted", "SELECT $$ ? $$ , ?", '$', "SELECT $$ ? $$ , $1"},
+		{"dollar quoted tag", "SELECT $tag$ ?'$x$ $tag$ , ?", '$', "SELECT $tag$ ?'$x$ $tag$ , $1"},
+		{"positional param is not a tag", "SELECT $1 , ? , $2", '$', "SELECT $1 , $1 , $2"},
+		{"double-quoted identifier", `SELECT "quoted ?" FROM t WHERE a = ?`, '$', `SELECT "quoted ?" FROM t WHERE a = $1`},
+		{"doubled double quote", `SELECT "a""?" , ?`, '$', `SELECT "a""?" , $1`},
+		{"backquoted identifier", "SELECT `quoted ?` FROM t WHERE a = ?", '$', "SELECT `quoted ?` FROM t WHERE a = $1"},
+		{"line comment", "SELECT ? -- ? here\n, ?", '$', "SELECT $1 -- ? here\n, $2"},
+		{"line comment at end", "SELECT ? -- ?", '$', "SELECT $1 -- ?"},
+		{"block comment", "SELECT /* ? */ ?", '$', "SELECT /* ? */ $1"},
+		{"nested block comment", "SELECT /* /* ? */ ? */ ?", '$', "SELECT /* /* ? */ ? */ $1"},
+		{"minus is not a comment", "SELECT a - ?", '$', "SELECT a - $1"},
+		{"unterminated literal", "SELECT ? , '?", '$', "SELECT $1 , '?"},
+
+		{"escaped question mark", "SELECT data ?? 'key' , ?", '$', "SELECT data ? 'key' , $1"},
+		{"escaped question mark mysql", "SELECT ?? , ?", '?', "SELECT ? , ?"},
+		{"jsonb any", "SELECT data ?| array['a'] , ?", '$', "SELECT data ?| array['a'] , $1"},
+		{"jsonb all", "SELECT data ?& array['a'] , ?", '$', "SELECT data ?& array['a'] , $1"},
+		{"concat after placeholder", "SELECT ?|| 'x'", '$', "SELECT $1|| 'x'"},
+		{"and after placeholder", "SELECT ?&& b", '$', "SELECT $1&& b"},
+		{"mysql bitwise or", "UPDATE t SET flags = ?|4", '?', "UPDATE t SET flags = ?|4"},
+		{"sqlite bitwise and", "SELECT ?&4", 0, "SELECT ?&4"},
+		{"mssql bitwise or", "SELECT ?|4", '@', "SELECT @1|4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertStaticPlaceholders(tt.sql, tt.prefix); got != tt.want {
+				t.Errorf("ConvertStaticPlaceholders(%q, %q) = %q, want %q", tt.sql, tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticPlaceholderCount(t *testing.T) {
+	tests := []struct {
+		sql    string
+		prefix byte
+		want   int
+	}{
+		{"SELECT ?, ?", '$', 2},
+		{"SELECT '?', \"?\", `?`, $$?$$, ? -- ?\n/* ? */", '$', 1},
+		{"SELECT data ?? 'k', data ?| a, data ?& b, ?", '$', 1},
+		{"UPDATE t SET flags = ?|4 WHERE id = ?", '?', 2},
+		{`SELECT 'it\'s ?', ?`, '?', 1},
+		{`SELECT 'a\', ?`, '$', 1},
+		{`SELECT 'a\', ?`, '?', 0},
+	}
+	for _, tt := range tests {
+		if got := StaticPlaceholderCount(tt.sql, tt.prefix); got != tt.want {
+			t.Errorf("StaticPlaceholderCount(%q, %q) = %d, want %d", tt.sql, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+// FuzzConvertStaticPlaceholders checks that ConvertStaticPlaceholders numbers every placeholder
+// StaticPlaceholderCount counts and leaves sql without `?` untouched
+func FuzzConvertStaticPlaceholders(f *testing.F) {
+	seeds := []string{
+		"SELECT * FROM t WHERE a = ? AND b = ?",
+		"SELECT 'it''s ?', ?",
+		`SELECT E'\'?', ?`,
+		`SELECT 'it\'s ?', ?`,
+		"SELECT $tag$ ? $tag$, ?",
+		`SELECT "quoted ?", ?`,
+		"SELECT `quoted ?`, ?",
+		"SELECT ? -- ?\n, ?",
+		"SELECT /* /* ? */ */ ?",
+		"SELECT data ?? 'key', ?",
+		"SELECT data ?| array['a'], data ?& array['b'], ?|| 'x'",
+		"UPDATE t SET flags = ?|4",
+		"'",
+		"$a$",
+	}
+	for _, seed := range seeds {
+		for _, prefix := range []byte{'$', '?', 0, '@'} {
+			f.Add(seed, prefix)
+		}
+	}
+	f.Fuzz(func(t *testing.T, sql string, prefix byte) {
+		if prefix != '$' && prefix != '?' && prefix != 0 && prefix != '@' && prefix != ':' {
+			return
+		}
+		got := ConvertStaticPlaceholders(sql, prefix)
+		if !strings.Contains(sql, "?") {
+			if got != sql {
+				t.Fatalf("sql without ? changed: %q -> %q", sql, got)
+			}
+			return
+		}
+		n := StaticPlaceholderCount(sql, prefix)
+		if prefix == '?' || prefix == 0 {
+			if len(got) > len(sql) {
+				t.Fatalf("%q -> %q grew", sql, got)
+			}
+			return
+		}
+		for k := 1; k <= n; k++ {
+			if !strings.Contains(got, string(prefix)+strconv.Itoa(k)) {
+				t.Fatalf("%q -> %q: %d placeholders, missing %c%d", sql, got, n, prefix, k)
+			}
+		}
+	})
+}
@@ -2,21 +2,41 @@ package sqldb
 
 import "context"
 
-type DBHandle interface {
+// Queryer is the set of query methods shared by DBHandle and Tx,
+// so the same code can run inside and outside a transaction
+type Queryer interface {
 	// Exec executes SQL statement like INSERT, UPDATE, DELETE.
 	Exec(ctx context.Context, query string, args ...any) (Result, error) // Executes General SQL Statement(s)
 
 	QueryRows(ctx context.Context, query string, args ...any) (Rows, error) // Eager. Fail upfront on statement execution
 	QueryRow(ctx context.Context, query string, args ...any) Row            // Lazy. only fails at Scan()
 
+	// SendBatch sends all queued queries at once.
+	// The returned Rows is positioned on the first result set; use NextResultSet() to advance
+	SendBatch(ctx context.Context, batch *Batch) (Rows, error)
+
 	// CopyFrom inserts many rows at once into a table, not executing individual INSERT statements.
 	// You stream all the rows in one operation
 	CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error)
 
-	Listen(ctx context.Context, channel string) (<-chan Notification, error)
 	Prepare(ctx context.Context, query string) (PreparedStmt, error)
 
 	// InsertStmt - Single INSERT statement, placeholders only
-	// to guarantee Result.LastInsertedId() works for auto-increment `id`
+	// to guarantee Result.LastInsertedId() works for auto-increment `id`.
+	// For a multi-row INSERT, LastInsertId() is the id of the first row, as in MySQL
 	InsertStmt(ctx context.Context, query string, args ...any) (Result, error)
+
+	// InsertReturning runs a single INSERT without RETURNING and returns one row per inserted row,
+	// holding its generated keyColumn (any type, e.g. UUID). pgsql/sqlite append `RETURNING keyColumn`;
+	// mysql derives consecutive ids from LastInsertId and RowsAffected, so only for AUTO_INCREMENT keys
+	// and not for upserts. See InsertKeys
+	InsertReturning(ctx context.Context, keyColumn string, query string, args ...any) (Rows, error)
+}
+
+type DBHandle interface {
+	Queryer
+
+	Listen(ctx context.Context, channel string) (<-chan Notification, error)
+	// Notify publishes a payload to all listeners of the channel
+	Notify(ctx context.Context, channel string, payload string) error
 }
@@ -1,5 +1,58 @@
 package sqldb
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var ErrNoRows = errors.New("no rows found")
+
+// Portable error kinds. Impls wrap DBMS errors into *Error, so callers can test with errors.Is
+// e.g. errors.Is(err, sqldb.ErrUniqueViolation)
+var (
+	ErrUniqueViolation      = errors.New("unique violation")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrNotNullViolation     = errors.New("not-null violation")
+	ErrCheckViolation       = errors.New("check violation")
+	ErrDeadlock             = errors.New("deadlock")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrConnectionLost       = errors.New("connection lost")
+	ErrQueryCanceled        = errors.New("query canceled")
+)
+
+// Error is a DBMS error classified into a portable Kind.
+// The original driver error stays reachable with errors.As (e.g. *pgconn.PgError, *mysql.MySQLError)
+type Error struct {
+	Kind       error  // one of the Err* kinds above
+	Code       string // DBMS-specific: SQLSTATE for pgsql, error number for mysql, extended result code for sqlite
+	Constraint string // if available
+	Table      string // if available
+	Column     string // if available
+	Err        error  // original driver error
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Kind.Error())
+	if e.Constraint != "" {
+		sb.WriteString(" (constraint ")
+		sb.WriteString(e.Constraint)
+		sb.WriteString(")")
+	}
+	if e.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap exposes both the Kind (for errors.Is) and the driver error (for errors.As)
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// IsRetryable reports whether err is a transient transaction failure
+// worth retrying as a whole transaction (see WithTx)
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrDeadlock) || errors.Is(err, ErrSerializationFailure)
+}
@@ -0,0 +1,418 @@
+package sqldb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/responses"
+)
+
+type ExportFormat string
+
+const (
+	ExportJSON   ExportFormat = "application/json"     // one JSON array of row objects
+	ExportNDJSON ExportFormat = "application/x-ndjson" // one row object per line
+	ExportCSV    ExportFormat = "text/csv"             // header row of column names, then rows
+)
+
+// ExportErrorTrailer is the HTTP trailer holding the error that ended an export mid-stream
+const ExportErrorTrailer = "X-Export-Error"
+
+// ExportOptions - zero value works
+type ExportOptions struct {
+	DefaultFormat ExportFormat  // for a missing or wildcard Accept. default: ExportJSON
+	Filename      string        // without extension. if set, sent as an attachment
+	FlushRows     int           // flush after this many rows. default: 1000
+	FlushInterval time.Duration // or after this much time since the last flush. default: 1s
+	// CSVEscapeFormulas prefixes text cells starting with =, +, -, @, tab or CR with `'`,
+	// so spreadsheets don't run them as formulas (CSV injection). Numbers are not escaped
+	CSVEscapeFormulas bool
+}
+
+// NegotiateExportFormat picks the format of the highest quality in accept.
+// false if accept lists only unsupported types
+func NegotiateExportFormat(accept string, defaultFormat ExportFormat) (ExportFormat, bool) {
+	if defaultFormat == "" {
+		defaultFormat = ExportJSON
+	}
+	if strings.TrimSpace(accept) == "" {
+		return defaultFormat, true
+	}
+	var (
+		best    ExportFormat
+		bestQ   = -1.0
+		matched bool
+	)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		var format ExportFormat
+		switch mediaType {
+		case "application/json":
+			format = ExportJSON
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			format = ExportNDJSON
+		case "text/csv":
+			format = ExportCSV
+		case "*/*", "application/*":
+			format = defaultFormat
+		case "text/*":
+			format = ExportCSV
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ, matched = format, q, true
+		}
+	}
+	return best, matched
+}
+
+// ExportResponse is QueryAllItemsResponse streaming rows to the response instead of loading them,
+// for large exports. See WriteExport
+func ExportResponse(q Queryer, rawStmt string, opts *ExportOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = WriteExport(w, r, q, opts, rawStmt)
+	}
+}
+
+// WriteExport runs query with the request ctx and streams the rows as JSON, NDJSON or CSV
+// by the Accept header (406 if none is acceptable), flushing periodically.
+// Errors before the first row get an error status. Once streaming, the status is already 200,
+// so an error ends the body with a final record ({"error": "..."} in JSON and NDJSON, a `#error` row in CSV)
+// and is set in the ExportErrorTrailer trailer; clients must check for it. Rows are written whole,
+// so the JSON stays valid after an encoding error. If the connection itself fails, the body is truncated.
+// Returns the error, already written and logged
+func WriteExport(w http.ResponseWriter, r *http.Request, q Queryer, opts *ExportOptions, query string, args ...any) error {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	format, ok := NegotiateExportFormat(r.Header.Get("Accept"), opts.DefaultFormat)
+	if !ok {
+		err := fmt.Errorf("not acceptable: %s", r.Header.Get("Accept"))
+		responses.WriteSimpleErrorJSON(w, http.StatusNotAcceptable, err.Error())
+		return err
+	}
+
+	ctx := r.Context()
+	rows, err := q.QueryRows(ctx, query, args...)
+	if err != nil {
+		responses.WriteSimpleErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("[ERROR] SQL failed to query rows. %v", err))
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		responses.WriteSimpleErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("[ERROR] SQL failed to get columns. %v", err))
+		return err
+	}
+	// read ahead one row so an error of the first one still gets an error status
+	hasRow := rows.Next()
+	if !hasRow {
+		if err = rows.Err(); err != nil {
+			responses.WriteSimpleErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("[ERROR] SQL failed to iterate rows. %v", err))
+			return err
+		}
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", exportContentType(format))
+	header.Set("Trailer", ExportErrorTrailer)
+	header.Set("X-Content-Type-Options", "nosniff")
+	if opts.Filename != "" {
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": opts.Filename + exportExtension(format),
+		}))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	bw := bufio.NewWriter(w)
+	ew := newExportWriter(format, bw, opts)
+	err = streamRows(rows, columns, hasRow, ew, func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := ew.flush(); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if err := http.NewResponseController(w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}, opts)
+	if err != nil {
+		if ctx.Err() == nil {
+			ew.writeError(err)
+		}
+		log.Printf("[ERROR] export ended mid-stream: %v", err)
+	}
+	ew.finish()
+	_ = ew.flush()
+	if flushErr := bw.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		header.Set(ExportErrorTrailer, err.Error())
+	}
+	return err
+}
+
+// streamRows writes the rows, the current one first if hasRow, calling flush periodically
+func streamRows(rows Rows, columns []string, hasRow bool, ew exportWriter, flush func() error, opts *ExportOptions) error {
+	flushRows := opts.FlushRows
+	if flushRows <= 0 {
+		flushRows = 1000
+	}
+	flushInterval := opts.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	if err := ew.writeHeader(columns); err != nil {
+		return err
+	}
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	pending := 0
+	lastFlush := time.Now()
+	for ; hasRow; hasRow = rows.Next() {
+		clear(values)
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Errorf("scan failed. %w", err)
+		}
+		if err := ew.writeRow(columns, values); err != nil {
+			return err
+		}
+		pending++
+		if pending >= flushRows || time.Since(lastFlush) >= flushInterval {
+			if err := flush(); err != nil {
+				return err
+			}
+			pending = 0
+			lastFlush = time.Now()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error during iterating rows. %w", err)
+	}
+	return nil
+}
+
+func exportContentType(format ExportFormat) string {
+	if format == ExportCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return string(format)
+}
+
+func exportExtension(format ExportFormat) string {
+	switch format {
+	case ExportNDJSON:
+		return ".ndjson"
+	case ExportCSV:
+		return ".csv"
+	default:
+		return ".json"
+	}
+}
+
+// exportWriter encodes rows of one format
+type exportWriter interface {
+	writeHeader(columns []string) error
+	writeRow(columns []string, values []any) error
+	writeError(err error)
+	finish()
+	flush() error // into the underlying writer
+}
+
+func newExportWriter(format ExportFormat, w io.Writer, opts *ExportOptions) exportWriter {
+	switch format {
+	case ExportCSV:
+		return &csvExportWriter{w: csv.NewWriter(w), escapeFormulas: opts.CSVEscapeFormulas}
+	case ExportNDJSON:
+		return &jsonExportWriter{w: w, enc: newExportEncoder()}
+	default:
+		return &jsonExportWriter{w: w, enc: newExportEncoder(), array: true}
+	}
+}
+
+var exportEncoderOptions = []jsontext.Options{jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true)}
+
+func newExportEncoder() *jsontext.Encoder {
+	return jsontext.NewEncoder(io.Discard, exportEncoderOptions...)
+}
+
+// jsonExportWriter encodes each row as a top-level value into buf and writes it to w only once complete,
+// so a row failing to encode leaves no partial output. For a JSON array, the brackets and commas are written around them
+type jsonExportWriter struct {
+	w     io.Writer
+	enc   *jsontext.Encoder
+	buf   bytes.Buffer
+	array bool
+	rows  int
+}
+
+// begin resets the encoder (also out of the broken state of a failed row) to encode into buf
+func (j *jsonExportWriter) begin() {
+	j.buf.Reset()
+	j.enc.Reset(&j.buf, exportEncoderOptions...)
+}
+
+// commit writes the encoded value in buf to w, after a separator
+func (j *jsonExportWriter) commit() error {
+	if err := j.separate(); err != nil {
+		return err
+	}
+	_, err := j.w.Write(j.buf.Bytes())
+	return err
+}
+
+func (j *jsonExportWriter) writeHeader([]string) error {
+	if j.array {
+		_, err := io.WriteString(j.w, "[\n")
+		return err
+	}
+	return nil
+}
+
+func (j *jsonExportWriter) writeRow(columns []string, values []any) error {
+	j.begin()
+	if err := j.enc.WriteToken(jsontext.BeginObject); err != nil {
+		return err
+	}
+	for i, col := range columns {
+		if err := j.enc.WriteToken(jsontext.String(col)); err != nil {
+			return err
+		}
+		if err := json.MarshalEncode(j.enc, exportValue(values[i])); err != nil {
+			return fmt.Errorf("failed to encode column %q. %w", col, err)
+		}
+	}
+	if err := j.enc.WriteToken(jsontext.EndObject); err != nil {
+		return err
+	}
+	return j.commit()
+}
+
+func (j *jsonExportWriter) separate() error {
+	j.rows++
+	if j.array && j.rows > 1 {
+		_, err := io.WriteString(j.w, ",")
+		return err
+	}
+	return nil
+}
+
+func (j *jsonExportWriter) writeError(err error) {
+	j.begin()
+	if json.MarshalEncode(j.enc, map[string]string{"error": err.Error()}) == nil {
+		_ = j.commit()
+	}
+}
+
+func (j *jsonExportWriter) finish() {
+	if j.array {
+		_, _ = io.WriteString(j.w, "]\n")
+	}
+}
+
+func (j *jsonExportWriter) flush() error {
+	return nil // rows are written to w as they complete
+}
+
+type csvExportWriter struct {
+	w              *csv.Writer
+	record         []string
+	escapeFormulas bool
+}
+
+func (c *csvExportWriter) writeHeader(columns []string) error {
+	c.record = make([]string, len(columns))
+	return c.w.Write(columns)
+}
+
+func (c *csvExportWriter) writeRow(_ []string, values []any) error {
+	for i, value := range values {
+		value = exportValue(value)
+		c.record[i] = csvCell(value)
+		if _, text := value.(string); text && c.escapeFormulas {
+			c.record[i] = escapeFormula(c.record[i])
+		}
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvExportWriter) writeError(err error) {
+	_ = c.w.Write([]string{"#error", err.Error()})
+}
+
+func (c *csvExportWriter) finish() {}
+
+func (c *csvExportWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// exportValue makes driver values presentable: text as []byte (e.g. from mysql) becomes a string
+func exportValue(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+func csvCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// escapeFormula prefixes a cell a spreadsheet would run as a formula with `'`
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,143 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type QueryOp string
+
+const (
+	OpExec     QueryOp = "exec"
+	OpQuery    QueryOp = "query"
+	OpQueryRow QueryOp = "query_row"
+	OpBatch    QueryOp = "batch"
+	OpCopyFrom QueryOp = "copy_from"
+	OpCommit   QueryOp = "commit"
+	OpRollback QueryOp = "rollback"
+)
+
+// QueryEvent describes one query for QueryHooks.
+// Impls fill Op, SQL, Args, InTx and Prepared; the rest is filled by Instrumentation.
+// pgx runs a query while its rows are read, so pgsql reports queries when the Rows are closed
+type QueryEvent struct {
+	Op       QueryOp
+	SQL      string // CopyFrom: table name. Commit/Rollback: empty
+	Args     []any  // redacted, see Instrumentation.RedactArgs
+	InTx     bool
+	Prepared bool
+
+	Start        time.Time
+	Duration     time.Duration // until the impl call returns; pgsql queries and batches: until Rows.Close / Row.Scan
+	RowsAffected int64         // -1 if unknown. pgsql queries: rows returned
+	Err          error
+}
+
+// QueryHook observes queries. BeforeQuery may return a derived ctx (e.g. carrying a tracing span)
+// which is passed to the query and to AfterQuery
+type QueryHook interface {
+	BeforeQuery(ctx context.Context, e *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, e *QueryEvent)
+}
+
+// Instrumentation runs hooks around every query of a Client (its DBHandle, Txs and PreparedStmts).
+// Set it on the impl Client before Init. Hooks must be safe for concurrent use
+type Instrumentation struct {
+	Hooks []QueryHook
+	// RedactArgs transforms args before hooks see them. default: RedactAllArgs
+	RedactArgs func(query string, args []any) []any
+}
+
+// RedactAllArgs replaces every arg with its type, e.g. "<redacted string>"
+func RedactAllArgs(_ string, args []any) []any {
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		redacted[i] = fmt.Sprintf("<redacted %T>", arg)
+	}
+	return redacted
+}
+
+// KeepArgs passes args as-is. Only for dev; args may hold personal data and secrets
+func KeepArgs(_ string, args []any) []any {
+	return args
+}
+
+// Enabled reports whether any hook is set. Safe on a nil Instrumentation
+func (in *Instrumentation) Enabled() bool {
+	return in != nil && len(in.Hooks) > 0
+}
+
+func noopQueryDone(int64, error) {}
+
+// Begin runs the BeforeQuery hooks and returns the ctx for the query
+// and a func reporting its outcome to the AfterQuery hooks (in reverse order).
+// Safe on a nil Instrumentation
+func (in *Instrumentation) Begin(ctx context.Context, e QueryEvent) (context.Context, func(rowsAffected int64, err error)) {
+	if !in.Enabled() {
+		return ctx, noopQueryDone
+	}
+	if e.Args != nil {
+		redact := in.RedactArgs
+		if redact == nil {
+			redact = RedactAllArgs
+		}
+		e.Args = redact(e.SQL, e.Args)
+	}
+	e.RowsAffected = -1
+	for _, hook := range in.Hooks {
+		ctx = hook.BeforeQuery(ctx, &e)
+	}
+	e.Start = time.Now()
+	return ctx, func(rowsAffected int64, err error) {
+		e.Duration = time.Since(e.Start)
+		e.RowsAffected = rowsAffected
+		e.Err = err
+		for i := len(in.Hooks) - 1; i >= 0; i-- {
+			in.Hooks[i].AfterQuery(ctx, &e)
+		}
+	}
+}
+
+// RowsAffectedOf returns result.RowsAffected(), or -1 if unknown
+func RowsAffectedOf(result Result) int64 {
+	if result == nil {
+		return -1
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// SlowQueryLog logs queries taking Threshold or longer
+type SlowQueryLog struct {
+	Threshold time.Duration                 // default: 500ms
+	Logf      func(format string, v ...any) // default: log.Printf
+}
+
+var _ QueryHook = (*SlowQueryLog)(nil)
+
+const defaultSlowQueryThreshold = 500 * time.Millisecond
+
+func (l *SlowQueryLog) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (l *SlowQueryLog) AfterQuery(_ context.Context, e *QueryEvent) {
+	threshold := l.Threshold
+	if threshold <= 0 {
+		threshold = defaultSlowQueryThreshold
+	}
+	if e.Duration < threshold {
+		return
+	}
+	logf := l.Logf
+	if logf == nil {
+		logf = log.Printf
+	}
+	logf("[WARN] slow %s (%s, tx=%t, prepared=%t, rows=%d, err=%v): %s args=%v",
+		e.Op, e.Duration, e.InTx, e.Prepared, e.RowsAffected, e.Err, e.SQL, e.Args)
+}
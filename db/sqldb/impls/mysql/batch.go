@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+// sendBatch joins all queries into one multi-statement string (DSN: multiStatements=true)
+// and sends it in a single round trip. Args are interpolated client-side (see interpolate)
+// since server-side prepared statements can't hold multiple statements.
+// NOTE: MySQL produces result sets only for statements returning rows;
+// statements like INSERT/UPDATE don't get one of their own.
+func sendBatch(ctx context.Context, q querier, t tracer, loc *time.Location, batch *sqldb.Batch) (sqldb.Rows, error) {
+	if batch == nil || batch.Len() == 0 {
+		return nil, fmt.Errorf("empty batch")
+	}
+	query, err := joinBatch(batch, loc)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	for i, query := range batch.Queries {
+		if i > 0 {
+			sb.WriteString(";\n")
+		}
+		sb.WriteString(strings.TrimRight(strings.TrimSpace(query.SQL), ";"))
+		args = append(args, query.Args...)
+	}
+	// traced with the args, not the interpolated literals
+	ctx, done := t.begin(ctx, sqldb.OpBatch, sb.String(), args)
+	rows, err := q.QueryContext(ctx, query)
+	err = convertErr(err)
+	done(-1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{rows: rows}, nil
+}
@@ -5,20 +5,29 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
-	_ "github.com/go-sql-driver/mysql" // side-effect
+	"github.com/go-sql-driver/mysql" // registers "mysql" driver
 )
 
 type Client struct {
 	//sqldb.Client // [Embedded Interface]
 
-	Conf *sqldb.Conf
+	Conf   *sqldb.Conf
+	Outbox OutboxConf // Listen/Notify emulation. optional
+	// Instrumentation runs hooks around queries (tracing, metrics, slow query log). optional
+	Instrumentation *sqldb.Instrumentation
 
 	// db fields are implementation details, not exported
-	db  *sql.DB
-	dsn string
+	db     *sql.DB
+	dsn    string
+	loc    *time.Location // DSN loc, for the args interpolated into batches
+	outbox *outbox
 }
 
 // Ensure mysql.Client implements sqldb.Client interface
@@ -26,28 +35,99 @@ var _ sqldb.Client = (*Client)(nil)
 
 func (c *Client) Init() error {
 	var err error
-	c.dsn = fmt.Sprintf(
-		"%s:%s@tcp(%s:%d)/%s?parseTime=true&loc=%s&multiStatements=true",
-		c.Conf.User,
-		c.Conf.PW,
-		c.Conf.Host,
-		c.Conf.Port,
-		c.Conf.DB,
-		c.Conf.TZ,
-	)
-	if c.db, err = sql.Open(c.Conf.Driver, c.dsn); err != nil {
+	if c.dsn, err = c.buildDSN(); err != nil {
 		return err
 	}
-	c.db.SetConnMaxLifetime(time.Minute * 3)
-	c.db.SetMaxOpenConns(10)
-	c.db.SetMaxIdleConns(10)
-	if err = c.db.Ping(); err != nil {
+	driver := c.Conf.Driver
+	if driver == "" {
+		driver = "mysql"
+	}
+	if c.db, err = sql.Open(driver, c.dsn); err != nil {
+		return err
+	}
+
+	// Pool tuning (sqldb.Conf.Pool, defaults if not set)
+	pool := c.Conf.Pool
+	maxConns := orInt(pool.MaxConns, 10)
+	c.db.SetConnMaxLifetime(pool.MaxConnLifetime.Or(3 * time.Minute))
+	c.db.SetMaxOpenConns(maxConns)
+	c.db.SetMaxIdleConns(orInt(pool.MaxIdleConns, maxConns))
+	if pool.MaxConnIdleTime > 0 {
+		c.db.SetConnMaxIdleTime(time.Duration(pool.MaxConnIdleTime))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Conf.ConnectTimeout.Or(5*time.Second))
+	defer cancel()
+	if err = c.db.PingContext(ctx); err != nil {
+		_ = c.db.Close() // don't leak the *sql.DB; Init may be retried
+		c.db = nil
 		return err
 	}
+	c.outbox = newOutbox(c.Outbox)
 	log.Println("[INFO] mysql db initialized")
 	return nil
 }
 
+func (c *Client) buildDSN() (string, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = c.Conf.User
+	cfg.Passwd = c.Conf.PW
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(c.Conf.Host, strconv.Itoa(c.Conf.Port))
+	cfg.DBName = c.Conf.DB
+	cfg.ParseTime = true
+	// multiStatements: required for SendBatch, which interpolates its args itself;
+	// other queries keep server-side params
+	cfg.MultiStatements = true
+	cfg.Timeout = c.Conf.ConnectTimeout.Or(5 * time.Second)
+	if c.Conf.TZ != "" {
+		loc, err := time.LoadLocation(c.Conf.TZ)
+		if err != nil {
+			return "", fmt.Errorf("invalid tz %q: %w", c.Conf.TZ, err)
+		}
+		cfg.Loc = loc
+	}
+	c.loc = cfg.Loc
+	if c.Conf.AppName != "" {
+		cfg.ConnectionAttributes = "program_name:" + c.Conf.AppName
+	}
+
+	tlsConfig, err := c.Conf.TLS.Config(c.Conf.Host)
+	if err != nil {
+		return "", err
+	}
+	if tlsConfig != nil {
+		// registered by name so the config survives as a plain DSN string
+		tlsKey := fmt.Sprintf("sqldb-%s-%d-%s", c.Conf.Host, c.Conf.Port, c.Conf.DB)
+		if err = mysql.RegisterTLSConfig(tlsKey, tlsConfig); err != nil {
+			return "", err
+		}
+		cfg.TLSConfig = tlsKey
+	}
+
+	dsn := cfg.FormatDSN()
+	if len(c.Conf.Params) > 0 {
+		// extra params are appended raw, so driver options & system variables are both accepted
+		params := url.Values{}
+		for k, v := range c.Conf.Params {
+			params.Set(k, v)
+		}
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + params.Encode()
+	}
+	return dsn, nil
+}
+
+func orInt(n int, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
 func (c *Client) Close() error {
 	if c.db == nil {
 		return nil
@@ -56,13 +136,32 @@ func (c *Client) Close() error {
 }
 
 func (c *Client) DBHandle() sqldb.DBHandle {
-	return &DBHandle{db: c.db}
+	return &DBHandle{db: c.db, loc: c.loc, outbox: c.outbox, inst: c.Instrumentation}
 }
 
-func (c *Client) BeginTx(ctx context.Context) (sqldb.Tx, error) {
-	tx, err := c.db.BeginTx(ctx, nil)
+// BeginTx - TxOptions.Deferrable is ignored (PostgreSQL only)
+func (c *Client) BeginTx(ctx context.Context, opts *sqldb.TxOptions) (sqldb.Tx, error) {
+	tx, err := c.db.BeginTx(ctx, txOptions(opts))
 	if err != nil {
-		return nil, err
+		return nil, convertErr(err)
+	}
+	return &Tx{tx: tx, loc: c.loc, inst: c.Instrumentation}, nil
+}
+
+func txOptions(opts *sqldb.TxOptions) *sql.TxOptions {
+	if opts == nil {
+		return nil
+	}
+	sqlOpts := &sql.TxOptions{ReadOnly: opts.ReadOnly}
+	switch opts.IsoLevel {
+	case sqldb.IsoLevelReadUncommitted:
+		sqlOpts.Isolation = sql.LevelReadUncommitted
+	case sqldb.IsoLevelReadCommitted:
+		sqlOpts.Isolation = sql.LevelReadCommitted
+	case sqldb.IsoLevelRepeatableRead:
+		sqlOpts.Isolation = sql.LevelRepeatableRead
+	case sqldb.IsoLevelSerializable:
+		sqlOpts.Isolation = sql.LevelSerializable
 	}
-	return &Tx{tx: tx}, nil
+	return sqlOpts
 }
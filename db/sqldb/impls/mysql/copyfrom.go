@@ -0,0 +1,129 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	maxPlaceholders      = 65535    // MySQL prepared statement limit
+	defaultMaxPacketSize = 4 << 20  // MySQL 5.7 default, used if @@max_allowed_packet can't be read
+	packetSafetyMargin   = 64 << 10 // headroom for protocol overhead
+)
+
+// batchInsert streams rows as chunked multi-row `INSERT ... VALUES (...),(...)`
+// statements within tx. Each chunk stays under max_allowed_packet and the placeholder limit.
+// Returns the number of rows actually inserted (sum of RowsAffected)
+func batchInsert(ctx context.Context, tx *sql.Tx, table string, columns []string, rows [][]any) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("CopyFrom requires at least one column")
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	maxPacket := int64(defaultMaxPacketSize)
+	if err := tx.QueryRowContext(ctx, "SELECT @@max_allowed_packet").Scan(&maxPacket); err != nil {
+		maxPacket = defaultMaxPacketSize
+	}
+	budget := maxPacket - packetSafetyMargin
+	if budget <= 0 {
+		budget = maxPacket
+	}
+
+	quotedCols := make([]string, len(columns))
+	for i, col := range columns {
+		quotedCols[i] = quoteIdent(col)
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", quoteIdent(table), strings.Join(quotedCols, ", "))
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	maxRowsByPlaceholders := maxPlaceholders / len(columns)
+
+	var (
+		total     int64
+		chunkArgs = make([]any, 0, len(columns)*min(len(rows), maxRowsByPlaceholders))
+		chunkRows int
+		chunkSize = int64(len(prefix))
+	)
+
+	flush := func() error {
+		if chunkRows == 0 {
+			return nil
+		}
+		var sb strings.Builder
+		sb.Grow(len(prefix) + chunkRows*(len(tuple)+1))
+		sb.WriteString(prefix)
+		for i := 0; i < chunkRows; i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(tuple)
+		}
+		result, err := tx.ExecContext(ctx, sb.String(), chunkArgs...)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		total += affected
+		chunkArgs = chunkArgs[:0]
+		chunkRows = 0
+		chunkSize = int64(len(prefix))
+		return nil
+	}
+
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return total, fmt.Errorf("row %d has %d values, expected %d", i, len(row), len(columns))
+		}
+		rowSize := int64(len(tuple) + 1)
+		for _, v := range row {
+			rowSize += estimateArgSize(v)
+		}
+		if rowSize+int64(len(prefix)) > budget {
+			return total, fmt.Errorf("row %d (~%d bytes) exceeds max_allowed_packet (%d)", i, rowSize, maxPacket)
+		}
+		if chunkRows >= maxRowsByPlaceholders || chunkSize+rowSize > budget {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+		chunkArgs = append(chunkArgs, row...)
+		chunkRows++
+		chunkSize += rowSize
+	}
+	if err := flush(); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+// estimateArgSize approximates the wire size of a bound value.
+// Overestimates slightly (length prefix, type bytes) so chunks stay under the packet limit
+func estimateArgSize(v any) int64 {
+	const overhead = 9 // length-encoded prefix + type info
+	switch val := v.(type) {
+	case nil:
+		return 1
+	case string:
+		return int64(len(val)) + overhead
+	case []byte:
+		return int64(len(val)) + overhead
+	case time.Time:
+		return 12 + overhead
+	case fmt.Stringer:
+		return int64(len(val.String())) + overhead
+	default:
+		return 8 + overhead // numeric, bool
+	}
+}
+
+// quoteIdent quotes an identifier with backticks, escaping embedded backticks
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
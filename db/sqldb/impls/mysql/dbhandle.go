@@ -3,8 +3,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"strings"
+	"time"
 
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
 )
@@ -12,65 +11,81 @@ import (
 type DBHandle struct {
 	// sqldb.DBHandle // [Interface]
 
-	db *sql.DB
+	db     *sql.DB
+	loc    *time.Location
+	outbox *outbox
+	inst   *sqldb.Instrumentation
 }
 
 // Ensure mysql.DBHandle implements sqldb.DBHandle interface
 var _ sqldb.DBHandle = (*DBHandle)(nil)
 
 func (h *DBHandle) Exec(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
-	result, err := h.db.ExecContext(ctx, query, args...)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
-	if err != nil {
-		return nil, err
-	}
-	return &Result{result: result}, nil
+	return exec(ctx, h.db, tracer{inst: h.inst}, query, args...)
 }
 
 func (h *DBHandle) QueryRows(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
-	rows, err := h.db.QueryContext(ctx, query, args...)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
-	if err != nil {
-		return nil, err
-	}
-	return &Rows{rows: rows}, nil
+	return queryRows(ctx, h.db, tracer{inst: h.inst}, query, args...)
 }
 
 func (h *DBHandle) QueryRow(ctx context.Context, query string, args ...any) sqldb.Row {
-	row := h.db.QueryRowContext(ctx, query, args...)
-	return &Row{row: row}
+	return queryRow(ctx, h.db, tracer{inst: h.inst}, query, args...)
 }
 
-// CopyFrom - params: table, columns, rows
-func (h *DBHandle) CopyFrom(_ context.Context, _ string, _ []string, _ [][]any) (int64, error) {
-	// MySQL doesn't have native COPY
-	// ToDo: emulate batch insert
-	return 0, fmt.Errorf("method `CopyFrom` not supported for MySQL")
+func (h *DBHandle) SendBatch(ctx context.Context, batch *sqldb.Batch) (sqldb.Rows, error) {
+	return sendBatch(ctx, h.db, tracer{inst: h.inst}, h.loc, batch)
 }
 
-// Listen - param: channel
-func (h *DBHandle) Listen(_ context.Context, _ string) (<-chan sqldb.Notification, error) {
-	return nil, fmt.Errorf("method `Listen` not supported for MySQL")
+// CopyFrom - MySQL doesn't have native COPY.
+// Emulated by chunked multi-row INSERT statements inside one transaction; all or nothing
+func (h *DBHandle) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	ctx, done := tracer{inst: h.inst}.begin(ctx, sqldb.OpCopyFrom, table, nil)
+	count, err := h.copyFrom(ctx, table, columns, rows)
+	done(count, err)
+	return count, err
 }
 
-func (h *DBHandle) InsertStmt(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
-	trimmed := strings.TrimSpace(query)
-	if !strings.HasPrefix(strings.ToUpper(trimmed), "INSERT") {
-		return nil, fmt.Errorf("InsertStmt must start with INSERT")
+func (h *DBHandle) copyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, convertErr(err)
 	}
-	result, err := h.db.ExecContext(ctx, query, args...)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
+	count, err := batchInsert(ctx, tx, table, columns, rows)
 	if err != nil {
-		return nil, err
+		_ = tx.Rollback()
+		return 0, convertErr(err)
 	}
-	return &Result{result: result}, nil
+	if err = tx.Commit(); err != nil {
+		return 0, convertErr(err)
+	}
+	return count, nil
 }
 
-func (h *DBHandle) Prepare(ctx context.Context, query string) (sqldb.PreparedStmt, error) {
-	stmt, err := h.db.PrepareContext(ctx, query)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
-	if err != nil {
+// Listen - MySQL has no LISTEN/NOTIFY.
+// Emulated by polling the outbox table (see OutboxConf); only notifications published after Listen are delivered
+func (h *DBHandle) Listen(ctx context.Context, channel string) (<-chan sqldb.Notification, error) {
+	if err := h.outbox.ensureTable(ctx, h.db); err != nil {
 		return nil, err
 	}
-	return &PreparedStmt{stmt: stmt}, nil
+	return h.outbox.listen(ctx, h.db, channel)
+}
+
+// Notify publishes a payload on a channel through the outbox table
+func (h *DBHandle) Notify(ctx context.Context, channel string, payload string) error {
+	if err := h.outbox.ensureTable(ctx, h.db); err != nil {
+		return err
+	}
+	return convertErr(h.outbox.notify(ctx, h.db, channel, payload))
+}
+
+func (h *DBHandle) InsertStmt(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
+	return insertStmt(ctx, h.db, tracer{inst: h.inst}, query, args...)
+}
+
+func (h *DBHandle) InsertReturning(ctx context.Context, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	return insertReturning(ctx, h.db, tracer{inst: h.inst}, keyColumn, query, args...)
+}
+
+func (h *DBHandle) Prepare(ctx context.Context, query string) (sqldb.PreparedStmt, error) {
+	return prepare(ctx, h.db, tracer{inst: h.inst}, query)
 }
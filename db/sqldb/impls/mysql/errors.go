@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"github.com/go-sql-driver/mysql"
+)
+
+// Ensure mysql.Client implements sqldb.RetryClassifier
+var _ sqldb.RetryClassifier = (*Client)(nil)
+
+// IsRetryable - 1213 ER_LOCK_DEADLOCK, 1205 ER_LOCK_WAIT_TIMEOUT
+func (c *Client) IsRetryable(err error) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+	switch myErr.Number {
+	case 1213, 1205:
+		return true
+	}
+	return false
+}
+
+// MySQL doesn't report constraint details as fields; parse them from the message
+var (
+	// Duplicate entry 'a@b.c' for key 'users.email'
+	reDupKey = regexp.MustCompile(`for key '(?:[^']*\.)?([^'.]+)'`)
+	// ... a foreign key constraint fails (`db`.`child`, CONSTRAINT `fk` FOREIGN KEY (`col`) REFERENCES ...
+	reFK = regexp.MustCompile("`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(`([^`]+)`")
+	// Column 'name' cannot be null | Field 'name' doesn't have a default value
+	reColumn = regexp.MustCompile(`(?:Column|Field) '([^']+)'`)
+	// Check constraint 'chk' is violated.
+	reCheck = regexp.MustCompile(`[Cc]heck constraint '([^']+)'`)
+)
+
+// convertErr classifies a go-sql-driver/mysql error into *sqldb.Error.
+// Unclassified errors are returned as-is
+func convertErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return sqldb.ErrNoRows
+	}
+	var sqlErr *sqldb.Error
+	if errors.As(err, &sqlErr) {
+		return err // already converted
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		e := &sqldb.Error{Code: strconv.Itoa(int(myErr.Number)), Err: err}
+		switch myErr.Number {
+		case 1062, 1586: // ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME
+			e.Kind = sqldb.ErrUniqueViolation
+			if m := reDupKey.FindStringSubmatch(myErr.Message); m != nil {
+				e.Constraint = m[1]
+			}
+		case 1451, 1452, 1216, 1217: // ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2, and pre-5.5 variants
+			e.Kind = sqldb.ErrForeignKeyViolation
+			if m := reFK.FindStringSubmatch(myErr.Message); m != nil {
+				e.Table, e.Constraint, e.Column = m[1], m[2], m[3]
+			}
+		case 1048, 1364: // ER_BAD_NULL_ERROR, ER_NO_DEFAULT_FOR_FIELD
+			e.Kind = sqldb.ErrNotNullViolation
+			if m := reColumn.FindStringSubmatch(myErr.Message); m != nil {
+				e.Column = m[1]
+			}
+		case 3819: // ER_CHECK_CONSTRAINT_VIOLATED
+			e.Kind = sqldb.ErrCheckViolation
+			if m := reCheck.FindStringSubmatch(myErr.Message); m != nil {
+				e.Constraint = m[1]
+			}
+		case 1213: // ER_LOCK_DEADLOCK
+			e.Kind = sqldb.ErrDeadlock
+		case 1317, 3024: // ER_QUERY_INTERRUPTED, ER_QUERY_TIMEOUT (max_execution_time)
+			e.Kind = sqldb.ErrQueryCanceled
+		case 1053, 2006, 2013: // ER_SERVER_SHUTDOWN, CR_SERVER_GONE_ERROR, CR_SERVER_LOST
+			e.Kind = sqldb.ErrConnectionLost
+		default:
+			return err
+		}
+		return e
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return &sqldb.Error{Kind: sqldb.ErrQueryCanceled, Err: err}
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn),
+		errors.Is(err, sql.ErrConnDone), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return &sqldb.Error{Kind: sqldb.ErrConnectionLost, Err: err}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return &sqldb.Error{Kind: sqldb.ErrConnectionLost, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+// interpolate writes args into the `?` placeholders of query as SQL literals, for multi-statement batches
+// that server-side prepared statements can't hold. Only SendBatch uses it; other queries bind args on the server.
+// Strings and bytes are written as hex literals, so they are safe regardless of NO_BACKSLASH_ESCAPES
+func interpolate(query string, args []any, loc *time.Location) (string, error) {
+	literals := make([]string, len(args))
+	for i, arg := range args {
+		literal, err := sqlLiteral(arg, loc)
+		if err != nil {
+			return "", fmt.Errorf("arg %d: %w", i+1, err)
+		}
+		literals[i] = literal
+	}
+	n := 0
+	interpolated := sqldb.ReplaceStaticPlaceholders(query, '?', func(i int) string {
+		n++
+		if i < len(literals) {
+			return literals[i]
+		}
+		return "?"
+	})
+	if n != len(args) {
+		return "", fmt.Errorf("query %q has %d placeholders for %d args", query, n, len(args))
+	}
+	return interpolated, nil
+}
+
+func sqlLiteral(arg any, loc *time.Location) (string, error) {
+	v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'e', -1, 64), nil
+	case string:
+		return "_utf8mb4 X'" + hex.EncodeToString([]byte(v)) + "'", nil
+	case []byte:
+		if v == nil {
+			return "NULL", nil
+		}
+		return "X'" + hex.EncodeToString(v) + "'", nil
+	case time.Time:
+		if v.IsZero() {
+			return "'0000-00-00'", nil
+		}
+		return "'" + v.In(loc).Format("2006-01-02 15:04:05.999999") + "'", nil
+	}
+	return "", fmt.Errorf("unsupported type %T", v)
+}
+
+// joinBatch joins the queries of batch, with their args interpolated, into one multi-statement string
+func joinBatch(batch *sqldb.Batch, loc *time.Location) (string, error) {
+	var sb strings.Builder
+	for i, query := range batch.Queries {
+		if i > 0 {
+			sb.WriteString(";\n")
+		}
+		stmt, err := interpolate(strings.TrimRight(strings.TrimSpace(query.SQL), ";"), query.Args, loc)
+		if err != nil {
+			return "", fmt.Errorf("batch query %d: %w", i+1, err)
+		}
+		sb.WriteString(stmt)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+// keyRows are the AUTO_INCREMENT ids of a multi-row INSERT: MySQL reports the first one (LastInsertId)
+// and allocates the rest in steps of @@auto_increment_increment for a single INSERT ... VALUES
+type keyRows struct {
+	column  string
+	firstID int64
+	step    int64
+	count   int64
+	i       int64 // 1-based index of the current row
+}
+
+// Ensure mysql.keyRows implements sqldb.Rows interface
+var _ sqldb.Rows = (*keyRows)(nil)
+
+func (r *keyRows) Columns() ([]string, error) {
+	return []string{r.column}, nil
+}
+
+func (r *keyRows) ColumnTypes() ([]sqldb.ColumnType, error) {
+	return []sqldb.ColumnType{{Name: r.column, DatabaseType: "BIGINT", HasNullable: true}}, nil
+}
+
+func (r *keyRows) Next() bool {
+	if r.i >= r.count {
+		return false
+	}
+	r.i++
+	return true
+}
+
+// Scan accepts pointers to integer types and *any
+func (r *keyRows) Scan(dest ...any) error {
+	if len(dest) != 1 {
+		return fmt.Errorf("expected 1 destination argument in Scan, not %d", len(dest))
+	}
+	id := r.firstID + (r.i-1)*r.step
+	if p, ok := dest[0].(*any); ok {
+		*p = id
+		return nil
+	}
+	v := reflect.ValueOf(dest[0])
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("Scan destination must be a non-nil pointer, got %T", dest[0])
+	}
+	v = v.Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(id) {
+			return fmt.Errorf("key %d overflows %s", id, v.Type())
+		}
+		v.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if id < 0 || v.OverflowUint(uint64(id)) {
+			return fmt.Errorf("key %d overflows %s", id, v.Type())
+		}
+		v.SetUint(uint64(id))
+	default:
+		return fmt.Errorf("mysql generates only AUTO_INCREMENT integer keys; cannot scan into %s", v.Type())
+	}
+	return nil
+}
+
+func (r *keyRows) Close() error {
+	return nil
+}
+
+func (r *keyRows) Err() error {
+	return nil
+}
+
+func (r *keyRows) NextResultSet() bool {
+	return false
+}
@@ -0,0 +1,184 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+// OutboxConf configures the notification outbox table that emulates
+// PostgreSQL LISTEN/NOTIFY on MySQL. Zero values fall back to defaults.
+type OutboxConf struct {
+	Table        string        // default: sqldb_notifications
+	PollInterval time.Duration // default: 1s
+	Retention    time.Duration // rows older than this are considered consumed and deleted. default: 1m
+	BatchSize    int           // max rows fetched per poll. default: 500
+}
+
+const (
+	defaultOutboxTable        = "sqldb_notifications"
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxRetention    = time.Minute
+	defaultOutboxBatchSize    = 500
+	outboxCleanupEvery        = 10 // polls
+)
+
+// outbox is shared by all DBHandles of a Client
+type outbox struct {
+	conf  OutboxConf
+	table string // quoted
+
+	mu    sync.Mutex
+	ready bool
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func newOutbox(conf OutboxConf) *outbox {
+	if conf.Table == "" {
+		conf.Table = defaultOutboxTable
+	}
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = defaultOutboxPollInterval
+	}
+	if conf.Retention <= 0 {
+		conf.Retention = defaultOutboxRetention
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = defaultOutboxBatchSize
+	}
+	return &outbox{conf: conf, table: quoteIdent(conf.Table)}
+}
+
+// ensureTable creates the outbox table once per Client. Retried on the next call if it fails
+func (o *outbox) ensureTable(ctx context.Context, db *sql.DB) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.ready {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	channel VARCHAR(255) NOT NULL,
+	payload MEDIUMTEXT NOT NULL,
+	pid BIGINT UNSIGNED NOT NULL,
+	created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+	KEY idx_channel_id (channel, id),
+	KEY idx_created_at (created_at)
+)`, o.table))
+	if err != nil {
+		return err
+	}
+	o.ready = true
+	return nil
+}
+
+// notify inserts a notification row. pid records the publishing connection's CONNECTION_ID()
+func (o *outbox) notify(ctx context.Context, e execer, channel string, payload string) error {
+	_, err := e.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (channel, payload, pid) VALUES (?, ?, CONNECTION_ID())", o.table),
+		channel, payload,
+	)
+	return err
+}
+
+// listen polls the outbox for rows newer than the ones present at subscription time
+// and delivers them in id order. The channel is closed when ctx is done.
+// NOTE: ids are assigned at INSERT, not at COMMIT; a row committed after a larger id
+// has been delivered is skipped. Publish with autocommit (DBHandle.Notify) to keep this window small.
+func (o *outbox) listen(ctx context.Context, db *sql.DB, channel string) (<-chan sqldb.Notification, error) {
+	var lastID int64
+	err := db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT COALESCE(MAX(id), 0) FROM %s WHERE channel = ?", o.table), channel,
+	).Scan(&lastID)
+	if err != nil {
+		return nil, err
+	}
+
+	notifyCh := make(chan sqldb.Notification)
+	query := fmt.Sprintf(
+		"SELECT id, channel, payload, pid FROM %s WHERE channel = ? AND id > ? ORDER BY id LIMIT %d",
+		o.table, o.conf.BatchSize,
+	)
+
+	go func() {
+		defer close(notifyCh)
+
+		ticker := time.NewTicker(o.conf.PollInterval)
+		defer ticker.Stop()
+
+		polls := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			batch, err := o.fetch(ctx, db, query, channel, lastID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("[WARN] outbox poll failed for %s: %v", channel, err)
+				continue
+			}
+			for _, n := range batch {
+				select {
+				case notifyCh <- n.Notification:
+					lastID = n.id
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			polls++
+			if polls%outboxCleanupEvery == 0 {
+				if err = o.cleanup(ctx, db); err != nil && ctx.Err() == nil {
+					log.Printf("[WARN] outbox cleanup failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	return notifyCh, nil
+}
+
+type outboxRow struct {
+	sqldb.Notification
+	id int64
+}
+
+func (o *outbox) fetch(ctx context.Context, db *sql.DB, query string, channel string, afterID int64) ([]outboxRow, error) {
+	rows, err := db.QueryContext(ctx, query, channel, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []outboxRow
+	for rows.Next() {
+		var r outboxRow
+		if err = rows.Scan(&r.id, &r.Channel, &r.Payload, &r.PID); err != nil {
+			return nil, err
+		}
+		batch = append(batch, r)
+	}
+	return batch, rows.Err()
+}
+
+// cleanup deletes rows past the retention window; every listener has polled them by then
+func (o *outbox) cleanup(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE created_at < NOW(6) - INTERVAL ? MICROSECOND LIMIT 10000", o.table),
+		o.conf.Retention.Microseconds(),
+	)
+	return err
+}
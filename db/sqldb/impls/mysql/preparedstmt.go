@@ -8,18 +8,40 @@ import (
 )
 
 type PreparedStmt struct {
-	stmt *sql.Stmt
+	stmt   *sql.Stmt
+	sql    string
+	tracer tracer
 }
 
 // Ensure mysql.PreparedStmt implements sqldb.PreparedStmt interface
 var _ sqldb.PreparedStmt = (*PreparedStmt)(nil)
 
 func (p *PreparedStmt) Query(ctx context.Context, args ...any) (sqldb.Rows, error) {
-	return p.stmt.QueryContext(ctx, args...)
+	ctx, done := p.begin(ctx, sqldb.OpQuery, args)
+	rows, err := p.stmt.QueryContext(ctx, args...)
+	err = convertErr(err)
+	done(-1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{rows: rows}, nil
 }
 
 func (p *PreparedStmt) Exec(ctx context.Context, args ...any) (sqldb.Result, error) {
-	return p.stmt.ExecContext(ctx, args...)
+	ctx, done := p.begin(ctx, sqldb.OpExec, args)
+	result, err := p.stmt.ExecContext(ctx, args...)
+	if err != nil {
+		err = convertErr(err)
+		done(-1, err)
+		return nil, err
+	}
+	r := &Result{result: result}
+	done(sqldb.RowsAffectedOf(r), nil)
+	return r, nil
+}
+
+func (p *PreparedStmt) begin(ctx context.Context, op sqldb.QueryOp, args []any) (context.Context, func(int64, error)) {
+	return p.tracer.inst.Begin(ctx, sqldb.QueryEvent{Op: op, SQL: p.sql, Args: args, InTx: p.tracer.inTx, Prepared: true})
 }
 
 func (p *PreparedStmt) Close() error {
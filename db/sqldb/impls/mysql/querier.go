@@ -0,0 +1,127 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+// querier is implemented by *sql.DB and *sql.Tx
+// so DBHandle and Tx share the same query paths
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// tracer reports the queries of a DBHandle or Tx to the client's Instrumentation
+type tracer struct {
+	inst *sqldb.Instrumentation
+	inTx bool
+}
+
+func (t tracer) begin(ctx context.Context, op sqldb.QueryOp, query string, args []any) (context.Context, func(int64, error)) {
+	return t.inst.Begin(ctx, sqldb.QueryEvent{Op: op, SQL: query, Args: args, InTx: t.inTx})
+}
+
+func exec(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Result, error) {
+	ctx, done := t.begin(ctx, sqldb.OpExec, query, args)
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		err = convertErr(err)
+		done(-1, err)
+		return nil, err
+	}
+	r := &Result{result: result}
+	done(sqldb.RowsAffectedOf(r), nil)
+	return r, nil
+}
+
+func queryRows(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Rows, error) {
+	ctx, done := t.begin(ctx, sqldb.OpQuery, query, args)
+	rows, err := q.QueryContext(ctx, query, args...)
+	err = convertErr(err)
+	done(-1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{rows: rows}, nil
+}
+
+func queryRow(ctx context.Context, q querier, t tracer, query string, args ...any) sqldb.Row {
+	ctx, done := t.begin(ctx, sqldb.OpQueryRow, query, args)
+	rows, err := q.QueryContext(ctx, query, args...)
+	done(-1, convertErr(err))
+	return &Row{rows: rows, err: err}
+}
+
+func insertStmt(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Result, error) {
+	trimmed := strings.TrimSpace(query)
+	if !strings.HasPrefix(strings.ToUpper(trimmed), "INSERT") {
+		return nil, fmt.Errorf("InsertStmt must start with INSERT")
+	}
+	return exec(ctx, q, t, query, args...)
+}
+
+// insertReturning - keyColumn must be the AUTO_INCREMENT column. Keys are derived from LastInsertId (the first row)
+// stepping by @@auto_increment_increment, as MySQL allocates them for a simple INSERT ... VALUES
+// (see sqldb.CheckInsertReturning for the rejected forms)
+func insertReturning(ctx context.Context, q querier, t tracer, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	query, err := sqldb.CheckInsertReturning("mysql", keyColumn, query)
+	if err != nil {
+		return nil, err
+	}
+	result, err := insertStmt(ctx, q, t, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	firstID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 && firstID == 0 {
+		return nil, fmt.Errorf("INSERT generated no AUTO_INCREMENT key for %q", keyColumn)
+	}
+	step := int64(1)
+	if count > 1 {
+		if step, err = autoIncrementIncrement(ctx, q); err != nil {
+			return nil, err
+		}
+	}
+	return &keyRows{column: keyColumn, firstID: firstID, step: step, count: count}, nil
+}
+
+// autoIncrementIncrement returns @@auto_increment_increment, > 1 on multi-primary setups
+func autoIncrementIncrement(ctx context.Context, q querier) (int64, error) {
+	rows, err := q.QueryContext(ctx, "SELECT @@auto_increment_increment")
+	if err != nil {
+		return 0, convertErr(err)
+	}
+	defer rows.Close()
+	var step int64
+	if !rows.Next() {
+		return 0, fmt.Errorf("failed to read @@auto_increment_increment. %w", convertErr(rows.Err()))
+	}
+	if err = rows.Scan(&step); err != nil {
+		return 0, convertErr(err)
+	}
+	if step < 1 {
+		step = 1
+	}
+	return step, nil
+}
+
+func prepare(ctx context.Context, q querier, t tracer, query string) (sqldb.PreparedStmt, error) {
+	stmt, err := q.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return &PreparedStmt{stmt: stmt, sql: query, tracer: t}, nil
+}
@@ -2,22 +2,40 @@ package mysql
 
 import (
 	"database/sql"
-	"errors"
 
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
 )
 
+// Row is lazy; a query error surfaces at Scan.
+// Built on *sql.Rows (as *sql.Row itself is) so column names are available
 type Row struct {
-	row *sql.Row
+	rows *sql.Rows
+	err  error
 }
 
 // Ensure mysql.Row implements sqldb.Row interface
 var _ sqldb.Row = (*Row)(nil)
 
+func (r *Row) Columns() ([]string, error) {
+	if r.err != nil {
+		return nil, convertErr(r.err)
+	}
+	return r.rows.Columns()
+}
+
 func (r *Row) Scan(dest ...any) error {
-	err := r.row.Scan(dest...)
-	if errors.Is(err, sql.ErrNoRows) {
+	if r.err != nil {
+		return convertErr(r.err)
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return convertErr(err)
+		}
 		return sqldb.ErrNoRows
 	}
-	return err
+	if err := r.rows.Scan(dest...); err != nil {
+		return convertErr(err)
+	}
+	return convertErr(r.rows.Close())
 }
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"database/sql"
+	"strings"
 
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
 )
@@ -13,12 +14,24 @@ type Rows struct {
 // Ensure mysql.Rows implements sqldb.Rows interface
 var _ sqldb.Rows = (*Rows)(nil)
 
+func (r *Rows) Columns() ([]string, error) {
+	return r.rows.Columns()
+}
+
+func (r *Rows) ColumnTypes() ([]sqldb.ColumnType, error) {
+	colTypes, err := r.rows.ColumnTypes()
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return columnTypes(colTypes), nil
+}
+
 func (r *Rows) Next() bool {
 	return r.rows.Next()
 }
 
 func (r *Rows) Scan(dest ...any) error {
-	return r.rows.Scan(dest...)
+	return convertErr(r.rows.Scan(dest...))
 }
 
 func (r *Rows) Close() error {
@@ -26,10 +39,25 @@ func (r *Rows) Close() error {
 }
 
 func (r *Rows) NextResultSet() bool {
-	// MySQL via database/sql doesn't support multiple result sets here
-	return false
+	// multi-statement queries (e.g. SendBatch) yield one result set per row-returning statement
+	return r.rows.NextResultSet()
 }
 
 func (r *Rows) Err() error {
-	return r.rows.Err()
+	return convertErr(r.rows.Err())
+}
+
+func columnTypes(colTypes []*sql.ColumnType) []sqldb.ColumnType {
+	types := make([]sqldb.ColumnType, len(colTypes))
+	for i, ct := range colTypes {
+		t := sqldb.ColumnType{
+			Name:         ct.Name(),
+			DatabaseType: strings.ToUpper(ct.DatabaseTypeName()),
+		}
+		t.Nullable, t.HasNullable = ct.Nullable()
+		t.Length, t.HasLength = ct.Length()
+		t.Precision, t.Scale, t.HasPrecision = ct.DecimalSize()
+		types[i] = t
+	}
+	return types
 }
@@ -3,29 +3,90 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
 )
 
 type Tx struct {
-	tx *sql.Tx
+	tx   *sql.Tx
+	loc  *time.Location
+	inst *sqldb.Instrumentation
 }
 
 // Ensure mysql.Tx implements sqldb.Tx interface
 var _ sqldb.Tx = (*Tx)(nil)
 
-func (t *Tx) Commit(_ context.Context) error {
-	return t.tx.Commit()
+func (t *Tx) Commit(ctx context.Context) error {
+	_, done := t.tracer().begin(ctx, sqldb.OpCommit, "", nil)
+	err := convertErr(t.tx.Commit())
+	done(-1, err)
+	return err
 }
 
-func (t *Tx) Rollback(_ context.Context) error {
-	return t.tx.Rollback()
+func (t *Tx) Rollback(ctx context.Context) error {
+	_, done := t.tracer().begin(ctx, sqldb.OpRollback, "", nil)
+	err := convertErr(t.tx.Rollback())
+	done(-1, err)
+	return err
+}
+
+func (t *Tx) tracer() tracer {
+	return tracer{inst: t.inst, inTx: true}
 }
 
 func (t *Tx) Exec(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
-	return t.tx.ExecContext(ctx, query, args...)
+	return exec(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) QueryRows(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
+	return queryRows(ctx, t.tx, t.tracer(), query, args...)
 }
 
 func (t *Tx) Query(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
-	return t.tx.QueryContext(ctx, query, args...)
+	return t.QueryRows(ctx, query, args...)
+}
+
+func (t *Tx) QueryRow(ctx context.Context, query string, args ...any) sqldb.Row {
+	return queryRow(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) SendBatch(ctx context.Context, batch *sqldb.Batch) (sqldb.Rows, error) {
+	return sendBatch(ctx, t.tx, t.tracer(), t.loc, batch)
+}
+
+// CopyFrom - chunked multi-row INSERTs within this transaction
+func (t *Tx) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	ctx, done := t.tracer().begin(ctx, sqldb.OpCopyFrom, table, nil)
+	count, err := batchInsert(ctx, t.tx, table, columns, rows)
+	err = convertErr(err)
+	done(count, err)
+	return count, err
+}
+
+func (t *Tx) InsertStmt(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
+	return insertStmt(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) InsertReturning(ctx context.Context, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	return insertReturning(ctx, t.tx, t.tracer(), keyColumn, query, args...)
+}
+
+func (t *Tx) Prepare(ctx context.Context, query string) (sqldb.PreparedStmt, error) {
+	return prepare(ctx, t.tx, t.tracer(), query)
+}
+
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, "SAVEPOINT "+quoteIdent(name))
+	return convertErr(err)
+}
+
+func (t *Tx) RollbackTo(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+quoteIdent(name))
+	return convertErr(err)
+}
+
+func (t *Tx) Release(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+quoteIdent(name))
+	return convertErr(err)
 }
@@ -0,0 +1,40 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"github.com/jackc/pgx/v5"
+)
+
+// sendBatch queues all queries into a pgx.Batch (one round trip)
+// and returns Rows positioned on the first result set
+func sendBatch(ctx context.Context, q querier, t tracer, batch *sqldb.Batch) (sqldb.Rows, error) {
+	if batch == nil || batch.Len() == 0 {
+		return nil, fmt.Errorf("empty batch")
+	}
+	var batchSQL string
+	if t.inst.Enabled() {
+		batchSQL = batch.SQL()
+	}
+	ctx, done := t.begin(ctx, sqldb.OpBatch, batchSQL, nil)
+	pgxBatch := &pgx.Batch{}
+	for _, q := range batch.Queries {
+		pgxBatch.Queue(q.SQL, q.Args...)
+	}
+	results := q.SendBatch(ctx, pgxBatch)
+	first, err := results.Query()
+	if err != nil {
+		_ = results.Close()
+		err = convertErr(err)
+		done(-1, err)
+		return nil, err
+	}
+	return &Rows{
+		current:   first,
+		batch:     results,
+		remaining: batch.Len() - 1,
+		done:      done,
+	}, nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/url"
 	"time"
 
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -14,10 +16,13 @@ type Client struct {
 	//sqldb.Client // [Embedded Interface]
 
 	Conf *sqldb.Conf
+	// Instrumentation runs hooks around queries (tracing, metrics, slow query log). optional
+	Instrumentation *sqldb.Instrumentation
 
 	// internal fields are implementation details, not exported
-	pool *pgxpool.Pool
-	dsn  string
+	pool       *pgxpool.Pool
+	dsn        string
+	stmtCaches *connStmtCaches
 }
 
 // Ensure pgsql.Client implements sqldb.Client interface
@@ -25,33 +30,40 @@ var _ sqldb.Client = (*Client)(nil)
 
 func (c *Client) Init() error {
 	// DSN format for pgx (URL or key/value style)
-	// Note: sslmode=disable is often used for local dev, adjust as needed.
 	// PostgreSQL natively allows multiple statements in a single query string.
-	c.dsn = fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s?sslmode=disable&timezone=%s",
-		c.Conf.User,
-		c.Conf.PW,
-		c.Conf.Host,
-		c.Conf.Port,
-		c.Conf.DB,
-		c.Conf.TZ,
-	)
-	// or key/value DSN format (also supported):
-	// c.dsn = fmt.Sprintf(
-	//    "host=%s port=%d user=%s password=%s dbname=%s sslmode=disable TimeZone=%s",
-	//     c.Conf.Host, c.Conf.Port, c.Conf.User, c.Conf.PW, c.Conf.DB, c.Conf.TZ)
+	c.dsn = c.buildDSN()
 
 	config, err := pgxpool.ParseConfig(c.dsn)
 	if err != nil {
 		return fmt.Errorf("failed to parse pgx config: %w", err)
 	}
+	if serverName := c.Conf.TLS.ServerName; serverName != "" {
+		// sslmode sets up TLS for the host; the server name (SNI, verify-full) can't be given in the DSN
+		if config.ConnConfig.TLSConfig != nil {
+			config.ConnConfig.TLSConfig.ServerName = serverName
+		}
+		for _, fallback := range config.ConnConfig.Fallbacks {
+			if fallback.TLSConfig != nil {
+				fallback.TLSConfig.ServerName = serverName
+			}
+		}
+	}
 
-	// Pool tuning (adjust as needed)
-	config.MaxConns = 10
-	config.MinConns = 2
-	config.MaxConnLifetime = 3 * time.Minute
+	// Pool tuning (sqldb.Conf.Pool, defaults if not set)
+	pool := c.Conf.Pool
+	config.MaxConns = int32(orInt(pool.MaxConns, 10))
+	config.MinConns = int32(min(orInt(pool.MinConns, 2), int(config.MaxConns)))
+	config.MaxConnLifetime = pool.MaxConnLifetime.Or(3 * time.Minute)
+	if pool.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = time.Duration(pool.MaxConnIdleTime)
+	}
+	// prepared stmts die with their connection (lifetime/idle recycling)
+	c.stmtCaches = newConnStmtCaches(pool.StmtCacheSize)
+	config.BeforeClose = c.stmtCaches.forget
+	connectTimeout := c.Conf.ConnectTimeout.Or(5 * time.Second)
+	config.ConnConfig.ConnectTimeout = connectTimeout
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
 	defer cancel()
 
 	c.pool, err = pgxpool.NewWithConfig(ctx, config)
@@ -61,15 +73,67 @@ func (c *Client) Init() error {
 
 	// connection settings
 	if err = c.pool.Ping(ctx); err != nil {
+		c.pool.Close() // don't leak the pool; Init may be retried
+		c.pool = nil
 		return fmt.Errorf("postgres ping failed: %w", err)
 	}
 
-	log.Printf("[INFO] pgsql client initialized (dsn=%s)", c.dsn)
+	log.Printf("[INFO] pgsql client initialized (dsn=%s)", redactDSN(c.dsn))
 	return nil
 }
 
+// buildDSN - URL style. sslmode=disable unless Conf.TLS says otherwise
+func (c *Client) buildDSN() string {
+	params := url.Values{}
+	params.Set("sslmode", sqldb.TLSModeDisable)
+	if c.Conf.TLS.Mode != "" {
+		params.Set("sslmode", c.Conf.TLS.Mode)
+	}
+	if c.Conf.TLS.CAFile != "" {
+		params.Set("sslrootcert", c.Conf.TLS.CAFile)
+	}
+	if c.Conf.TLS.CertFile != "" {
+		params.Set("sslcert", c.Conf.TLS.CertFile)
+	}
+	if c.Conf.TLS.KeyFile != "" {
+		params.Set("sslkey", c.Conf.TLS.KeyFile)
+	}
+	if c.Conf.TZ != "" {
+		params.Set("timezone", c.Conf.TZ)
+	}
+	if c.Conf.AppName != "" {
+		params.Set("application_name", c.Conf.AppName)
+	}
+	for k, v := range c.Conf.Params {
+		params.Set(k, v)
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.Conf.User, c.Conf.PW),
+		Host:     fmt.Sprintf("%s:%d", c.Conf.Host, c.Conf.Port),
+		Path:     "/" + c.Conf.DB,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+func redactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "(unparsable dsn)"
+	}
+	return u.Redacted()
+}
+
+func orInt(n int, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
 func (c *Client) DBHandle() sqldb.DBHandle {
-	return &DBHandle{pool: c.pool}
+	return &DBHandle{pool: c.pool, caches: c.stmtCaches, inst: c.Instrumentation}
 }
 
 func (c *Client) Close() error {
@@ -81,18 +145,38 @@ func (c *Client) Close() error {
 	return nil
 }
 
-func (c *Client) BeginTx(ctx context.Context) (sqldb.Tx, error) {
+func (c *Client) BeginTx(ctx context.Context, opts *sqldb.TxOptions) (sqldb.Tx, error) {
 	if c.pool == nil {
 		return nil, fmt.Errorf("pgsql client not initialized")
 	}
-	conn, err := c.pool.Acquire(ctx)
+	// pool.BeginTx releases the connection back to the pool on Commit/Rollback
+	tx, err := c.pool.BeginTx(ctx, txOptions(opts))
 	if err != nil {
-		return nil, fmt.Errorf("acquire connection failed: %w", err)
+		return nil, fmt.Errorf("begin transaction failed: %w", convertErr(err))
 	}
-	tx, err := conn.Begin(ctx)
-	if err != nil {
-		conn.Release()
-		return nil, fmt.Errorf("begin transaction failed: %w", err)
+	return &Tx{tx: tx, caches: c.stmtCaches, inst: c.Instrumentation}, nil
+}
+
+func txOptions(opts *sqldb.TxOptions) pgx.TxOptions {
+	var pgxOpts pgx.TxOptions
+	if opts == nil {
+		return pgxOpts
+	}
+	switch opts.IsoLevel {
+	case sqldb.IsoLevelReadUncommitted:
+		pgxOpts.IsoLevel = pgx.ReadUncommitted
+	case sqldb.IsoLevelReadCommitted:
+		pgxOpts.IsoLevel = pgx.ReadCommitted
+	case sqldb.IsoLevelRepeatableRead:
+		pgxOpts.IsoLevel = pgx.RepeatableRead
+	case sqldb.IsoLevelSerializable:
+		pgxOpts.IsoLevel = pgx.Serializable
+	}
+	if opts.ReadOnly {
+		pgxOpts.AccessMode = pgx.ReadOnly
+	}
+	if opts.Deferrable {
+		pgxOpts.DeferrableMode = pgx.Deferrable
 	}
-	return &Tx{tx: tx}, nil
+	return pgxOpts
 }
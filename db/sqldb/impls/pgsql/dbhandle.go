@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
-	"time"
 
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
 	"github.com/jackc/pgx/v5"
@@ -13,50 +11,37 @@ import (
 )
 
 type DBHandle struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	caches *connStmtCaches
+	inst   *sqldb.Instrumentation
 }
 
 var _ sqldb.DBHandle = (*DBHandle)(nil)
 
 func (h *DBHandle) Exec(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
-	tag, err := h.pool.Exec(ctx, query, args...)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
-	if err != nil {
-		return nil, err
-	}
-	return &Result{tag: tag}, nil
+	return exec(ctx, h.pool, tracer{inst: h.inst}, query, args...)
 }
 
 func (h *DBHandle) QueryRows(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
-	rows, err := h.pool.Query(ctx, query, args...)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
-	if err != nil {
-		return nil, err
-	}
-	return &Rows{
-		conn:    nil, // pool manages connection, no need to release here
-		current: rows,
-		batch:   nil, // single query, no batch
-	}, nil
+	return queryRows(ctx, h.pool, tracer{inst: h.inst}, query, args...)
 }
 
 func (h *DBHandle) QueryRow(ctx context.Context, query string, args ...any) sqldb.Row {
-	row := h.pool.QueryRow(ctx, query, args...)
-	return &Row{row: row}
+	return queryRow(ctx, h.pool, tracer{inst: h.inst}, query, args...)
+}
+
+func (h *DBHandle) SendBatch(ctx context.Context, batch *sqldb.Batch) (sqldb.Rows, error) {
+	return sendBatch(ctx, h.pool, tracer{inst: h.inst}, batch)
 }
 
 func (h *DBHandle) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
-	src := pgx.CopyFromRows(rows)
-	count, err := h.pool.CopyFrom(ctx, pgx.Identifier{table}, columns, src)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
-	return count, err
+	return copyFrom(ctx, h.pool, tracer{inst: h.inst}, table, columns, rows)
 }
 
 func (h *DBHandle) Listen(ctx context.Context, channel string) (<-chan sqldb.Notification, error) {
 	conn, err := h.pool.Acquire(ctx)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
 	if err != nil {
-		return nil, err
+		return nil, convertErr(err)
 	}
 
 	notifyCh := make(chan sqldb.Notification)
@@ -79,6 +64,7 @@ func (h *DBHandle) Listen(ctx context.Context, channel string) (<-chan sqldb.Not
 			}
 			select {
 			case notifyCh <- sqldb.Notification{
+				PID:     notification.PID,
 				Channel: notification.Channel,
 				Payload: notification.Payload,
 			}:
@@ -91,38 +77,36 @@ func (h *DBHandle) Listen(ctx context.Context, channel string) (<-chan sqldb.Not
 	return notifyCh, nil
 }
 
-func (h *DBHandle) InsertStmt(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
-	trimmed := strings.TrimSpace(query)
-	if !strings.HasPrefix(strings.ToUpper(trimmed), "INSERT") {
-		return nil, fmt.Errorf("InsertStmt must start with INSERT")
-	}
-	// append RETURNING id if missing
-	if !strings.Contains(strings.ToUpper(query), "RETURNING") {
-		query += " RETURNING id"
-		var id int64
-		err := h.pool.QueryRow(ctx, query, args...).Scan(&id)
-		if err != nil {
-			return nil, err
-		}
-		return &Result{lastInsertID: id}, nil
+// maxNotifyPayloadSize - PostgreSQL requires NOTIFY payloads shorter than 8000 bytes (default config)
+const maxNotifyPayloadSize = 8000
+
+// Notify sends NOTIFY via pg_notify(), which accepts channel & payload as bind parameters
+func (h *DBHandle) Notify(ctx context.Context, channel string, payload string) error {
+	if len(payload) >= maxNotifyPayloadSize {
+		return fmt.Errorf("notify payload too large: %d bytes (must be < %d)", len(payload), maxNotifyPayloadSize)
 	}
+	_, err := h.pool.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return convertErr(err)
+}
 
-	tag, err := h.pool.Exec(ctx, query, args...)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
-	return &Result{tag: tag}, err
+func (h *DBHandle) InsertStmt(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
+	return insertStmt(ctx, h.pool, tracer{inst: h.inst}, query, args...)
+}
+
+func (h *DBHandle) InsertReturning(ctx context.Context, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	return insertReturning(ctx, h.pool, tracer{inst: h.inst}, keyColumn, query, args...)
 }
 
+// Prepare checks the SQL by preparing it on one pooled connection, which is released right away.
+// Other connections prepare it lazily on first use
 func (h *DBHandle) Prepare(ctx context.Context, query string) (sqldb.PreparedStmt, error) {
 	conn, err := h.pool.Acquire(ctx)
-	// NOTE: We can process a DBMS-specific error to produce a better abstracted error
 	if err != nil {
-		return nil, err
+		return nil, convertErr(err)
 	}
-	stmtName := fmt.Sprintf("stmt_%x", time.Now().UnixNano())
-	_, err = conn.Conn().Prepare(ctx, stmtName, query)
-	if err != nil {
-		conn.Release()
-		return nil, err
+	defer conn.Release()
+	if _, err = h.caches.prepare(ctx, conn.Conn(), query); err != nil {
+		return nil, convertErr(err)
 	}
-	return &PreparedStmt{conn: conn, stmtName: stmtName}, nil
+	return &PreparedStmt{pool: h.pool, caches: h.caches, inst: h.inst, sql: query}, nil
 }
@@ -0,0 +1,98 @@
+package pgsql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Ensure pgsql.Client implements sqldb.RetryClassifier
+var _ sqldb.RetryClassifier = (*Client)(nil)
+
+// IsRetryable - SQLSTATE 40001 serialization_failure, 40P01 deadlock_detected
+func (c *Client) IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case "40001", "40P01":
+		return true
+	}
+	return false
+}
+
+// convertErr classifies a pgx/pgconn error into *sqldb.Error.
+// Unclassified errors are returned as-is
+func convertErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sqldb.ErrNoRows
+	}
+	var sqlErr *sqldb.Error
+	if errors.As(err, &sqlErr) {
+		return err // already converted
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		kind := kindFromSQLState(pgErr.Code)
+		if kind == nil {
+			return err
+		}
+		return &sqldb.Error{
+			Kind:       kind,
+			Code:       pgErr.Code,
+			Constraint: pgErr.ConstraintName,
+			Table:      pgErr.TableName,
+			Column:     pgErr.ColumnName,
+			Err:        err,
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded), pgconn.Timeout(err):
+		return &sqldb.Error{Kind: sqldb.ErrQueryCanceled, Err: err}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, net.ErrClosed):
+		return &sqldb.Error{Kind: sqldb.ErrConnectionLost, Err: err}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return &sqldb.Error{Kind: sqldb.ErrConnectionLost, Err: err}
+	}
+	return err
+}
+
+// kindFromSQLState https://www.postgresql.org/docs/current/errcodes-appendix.html
+func kindFromSQLState(code string) error {
+	switch code {
+	case "23505": // unique_violation
+		return sqldb.ErrUniqueViolation
+	case "23503": // foreign_key_violation
+		return sqldb.ErrForeignKeyViolation
+	case "23502": // not_null_violation
+		return sqldb.ErrNotNullViolation
+	case "23514": // check_violation
+		return sqldb.ErrCheckViolation
+	case "40P01": // deadlock_detected
+		return sqldb.ErrDeadlock
+	case "40001": // serialization_failure
+		return sqldb.ErrSerializationFailure
+	case "57014": // query_canceled (statement_timeout, pg_cancel_backend)
+		return sqldb.ErrQueryCanceled
+	case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+		return sqldb.ErrConnectionLost
+	}
+	if strings.HasPrefix(code, "08") { // class 08: connection exception
+		return sqldb.ErrConnectionLost
+	}
+	return nil
+}
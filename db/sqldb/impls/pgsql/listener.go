@@ -0,0 +1,287 @@
+package pgsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReconnectedChannel is the Channel of the synthetic Notification a Listener emits
+// after re-establishing its connection. Notifications sent while disconnected are lost,
+// so consumers should resync their state when they receive it.
+// (a dotted name can't collide with an unquoted PostgreSQL channel name)
+const ReconnectedChannel = "pgsql.listener.reconnected"
+
+const (
+	listenerMinBackoff = 500 * time.Millisecond
+	listenerMaxBackoff = 30 * time.Second
+)
+
+var ErrListenerClosed = errors.New("listener closed")
+
+// Listener multiplexes LISTEN on many channels over one dedicated connection
+// (outside the pool, so it never starves pooled queries), and reconnects with backoff.
+type Listener struct {
+	connConfig *pgx.ConnConfig
+	notifyCh   chan sqldb.Notification
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	channels  map[string]struct{} // desired set; re-LISTENed on reconnect
+	ops       []listenerOp        // pending LISTEN/UNLISTEN for the live connection
+	interrupt context.CancelFunc  // wakes the loop blocked in WaitForNotification
+}
+
+type listenerOp struct {
+	channel string
+	listen  bool // false = UNLISTEN
+	reply   chan error
+}
+
+// NewListener connects a dedicated connection and LISTENs on the given channels.
+// The Listener stops when ctx is done or Close is called.
+func (c *Client) NewListener(ctx context.Context, channels ...string) (*Listener, error) {
+	if c.pool == nil {
+		return nil, fmt.Errorf("pgsql client not initialized")
+	}
+	l := &Listener{
+		connConfig: c.pool.Config().ConnConfig.Copy(),
+		notifyCh:   make(chan sqldb.Notification),
+		done:       make(chan struct{}),
+		channels:   make(map[string]struct{}, len(channels)),
+	}
+	for _, ch := range channels {
+		l.channels[ch] = struct{}{}
+	}
+	l.ctx, l.cancel = context.WithCancel(ctx)
+
+	conn, err := l.connect()
+	if err != nil {
+		l.cancel()
+		return nil, err
+	}
+	go l.run(conn)
+	return l, nil
+}
+
+// Notifications delivers notifications of all channels in arrival order,
+// plus a ReconnectedChannel event after each reconnect. Closed when the Listener stops
+func (l *Listener) Notifications() <-chan sqldb.Notification {
+	return l.notifyCh
+}
+
+// Add starts listening on channel.
+// While disconnected, it waits until LISTEN ran on the new connection (or ctx); the channel is LISTENed on reconnect either way
+func (l *Listener) Add(ctx context.Context, channel string) error {
+	return l.submit(ctx, listenerOp{channel: channel, listen: true})
+}
+
+// Remove stops listening on channel
+func (l *Listener) Remove(ctx context.Context, channel string) error {
+	return l.submit(ctx, listenerOp{channel: channel, listen: false})
+}
+
+// Channels returns the channels currently subscribed
+func (l *Listener) Channels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	channels := make([]string, 0, len(l.channels))
+	for ch := range l.channels {
+		channels = append(channels, ch)
+	}
+	return channels
+}
+
+// Close stops the Listener and closes its connection
+func (l *Listener) Close() error {
+	l.cancel()
+	<-l.done
+	return nil
+}
+
+func (l *Listener) submit(ctx context.Context, op listenerOp) error {
+	op.reply = make(chan error, 1)
+	l.mu.Lock()
+	if l.ctx.Err() != nil {
+		l.mu.Unlock()
+		return ErrListenerClosed
+	}
+	if op.listen {
+		l.channels[op.channel] = struct{}{}
+	} else {
+		delete(l.channels, op.channel)
+	}
+	l.ops = append(l.ops, op)
+	if l.interrupt != nil {
+		l.interrupt()
+	}
+	l.mu.Unlock()
+
+	select {
+	case err := <-op.reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.done:
+		return ErrListenerClosed
+	}
+}
+
+// connect opens the dedicated connection and LISTENs on the desired set.
+// Channels of pending ops are left to serve, which replies to each op once it ran on the new connection
+func (l *Listener) connect() (*pgx.Conn, error) {
+	conn, err := pgx.ConnectConfig(l.ctx, l.connConfig)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	pending := make(map[string]struct{}, len(l.ops))
+	for _, op := range l.ops {
+		pending[op.channel] = struct{}{}
+	}
+	channels := make([]string, 0, len(l.channels))
+	for ch := range l.channels {
+		if _, exists := pending[ch]; !exists {
+			channels = append(channels, ch)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, ch := range channels {
+		if _, err = conn.Exec(l.ctx, "LISTEN "+pgx.Identifier{ch}.Sanitize()); err != nil {
+			_ = conn.Close(context.Background())
+			return nil, fmt.Errorf("failed to LISTEN on %s: %w", ch, err)
+		}
+	}
+	return conn, nil
+}
+
+func (l *Listener) run(conn *pgx.Conn) {
+	defer close(l.done)
+	defer close(l.notifyCh)
+	defer func() {
+		l.mu.Lock()
+		ops := l.ops
+		l.ops = nil
+		l.mu.Unlock()
+		for _, op := range ops {
+			op.reply <- ErrListenerClosed
+		}
+	}()
+
+	for {
+		err := l.serve(conn)
+		_ = conn.Close(context.Background())
+		if l.ctx.Err() != nil {
+			return
+		}
+		log.Printf("[WARN] pgsql listener connection lost, reconnecting: %v", err)
+
+		if conn = l.reconnect(); conn == nil {
+			return
+		}
+		select {
+		case l.notifyCh <- sqldb.Notification{Channel: ReconnectedChannel}:
+		case <-l.ctx.Done():
+			_ = conn.Close(context.Background())
+			return
+		}
+	}
+}
+
+// reconnect retries with jittered exponential backoff. Returns nil once the Listener is stopped
+func (l *Listener) reconnect() *pgx.Conn {
+	backoff := listenerMinBackoff
+	for {
+		timer := time.NewTimer(backoff/2 + rand.N(backoff/2+1))
+		select {
+		case <-timer.C:
+		case <-l.ctx.Done():
+			timer.Stop()
+			return nil
+		}
+		conn, err := l.connect()
+		if err == nil {
+			log.Println("[INFO] pgsql listener reconnected")
+			return conn
+		}
+		if l.ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("[WARN] pgsql listener reconnect failed: %v", err)
+		backoff = min(backoff*2, listenerMaxBackoff)
+	}
+}
+
+// serve applies pending ops and waits for notifications until the connection fails or the Listener stops
+func (l *Listener) serve(conn *pgx.Conn) error {
+	for {
+		waitCtx, cancelWait := context.WithCancel(l.ctx)
+		l.mu.Lock()
+		ops := l.ops
+		l.ops = nil
+		l.interrupt = cancelWait
+		l.mu.Unlock()
+
+		for i, op := range ops {
+			stmt := "UNLISTEN "
+			if op.listen {
+				stmt = "LISTEN "
+			}
+			_, err := conn.Exec(l.ctx, stmt+pgx.Identifier{op.channel}.Sanitize())
+			if err != nil && conn.IsClosed() {
+				// connection lost: this and the remaining ops run (and reply) on the new connection
+				l.mu.Lock()
+				l.ops = slices.Concat(ops[i:], l.ops)
+				l.mu.Unlock()
+				cancelWait()
+				return err
+			}
+			if err != nil && op.listen {
+				l.mu.Lock()
+				delete(l.channels, op.channel)
+				l.mu.Unlock()
+			}
+			op.reply <- err
+		}
+
+		notification, err := conn.WaitForNotification(waitCtx)
+		interrupted := waitCtx.Err() != nil
+		cancelWait()
+
+		l.mu.Lock()
+		l.interrupt = nil
+		l.mu.Unlock()
+
+		if err != nil {
+			if l.ctx.Err() != nil {
+				return l.ctx.Err()
+			}
+			if interrupted && !conn.IsClosed() {
+				continue // woken up by Add/Remove
+			}
+			return err
+		}
+
+		select {
+		case l.notifyCh <- sqldb.Notification{
+			PID:     notification.PID,
+			Channel: notification.Channel,
+			Payload: notification.Payload,
+		}:
+		case <-l.ctx.Done():
+			return l.ctx.Err()
+		}
+	}
+}
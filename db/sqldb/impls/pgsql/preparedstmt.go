@@ -4,34 +4,90 @@ import (
 	"context"
 
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// PreparedStmt doesn't hold a connection: each call runs on a pooled connection (or the Tx's),
+// preparing the SQL there first if needed (see connStmtCaches)
 type PreparedStmt struct {
-	conn     *pgxpool.Conn
-	stmtName string
+	pool   *pgxpool.Pool // nil inside Tx
+	tx     pgx.Tx        // nil outside Tx
+	caches *connStmtCaches
+	inst   *sqldb.Instrumentation
+	sql    string
 }
 
 // Ensure pgsql.PreparedStmt implements sqldb.PreparedStmt interface
 var _ sqldb.PreparedStmt = (*PreparedStmt)(nil)
 
 func (p *PreparedStmt) Query(ctx context.Context, args ...any) (sqldb.Rows, error) {
-	rows, err := p.conn.Query(ctx, p.stmtName, args...)
+	ctx, done := p.begin(ctx, sqldb.OpQuery, args)
+	q, conn, name, err := p.acquire(ctx)
 	if err != nil {
+		done(-1, err)
 		return nil, err
 	}
-	return &Rows{current: rows}, nil
+	rows, err := q.Query(ctx, name, args...)
+	if err != nil {
+		err = convertErr(err)
+		done(-1, err)
+		release(conn)
+		return nil, err
+	}
+	return &Rows{conn: conn, current: rows, done: done}, nil
 }
 
 func (p *PreparedStmt) Exec(ctx context.Context, args ...any) (sqldb.Result, error) {
-	tag, err := p.conn.Exec(ctx, p.stmtName, args...)
+	ctx, done := p.begin(ctx, sqldb.OpExec, args)
+	q, conn, name, err := p.acquire(ctx)
+	if err != nil {
+		done(-1, err)
+		return nil, err
+	}
+	defer release(conn)
+	tag, err := q.Exec(ctx, name, args...)
+	err = convertErr(err)
+	done(tag.RowsAffected(), err)
 	if err != nil {
 		return nil, err
 	}
 	return &Result{tag: tag}, nil
 }
 
+func (p *PreparedStmt) begin(ctx context.Context, op sqldb.QueryOp, args []any) (context.Context, func(int64, error)) {
+	return p.inst.Begin(ctx, sqldb.QueryEvent{Op: op, SQL: p.sql, Args: args, InTx: p.tx != nil, Prepared: true})
+}
+
+// Close is a no-op: prepared stmts stay cached per connection until evicted or the connection is closed
 func (p *PreparedStmt) Close() error {
-	p.conn.Release()
 	return nil
 }
+
+// acquire returns the querier to run on, with the stmt prepared on its connection.
+// conn is the acquired pool connection to release, nil inside Tx
+func (p *PreparedStmt) acquire(ctx context.Context) (q querier, conn *pgxpool.Conn, name string, err error) {
+	if p.tx != nil {
+		name, err = p.caches.prepare(ctx, p.tx.Conn(), p.sql)
+		if err != nil {
+			return nil, nil, "", convertErr(err)
+		}
+		return p.tx, nil, name, nil
+	}
+	conn, err = p.pool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, "", convertErr(err)
+	}
+	name, err = p.caches.prepare(ctx, conn.Conn(), p.sql)
+	if err != nil {
+		conn.Release()
+		return nil, nil, "", convertErr(err)
+	}
+	return conn, conn, name, nil
+}
+
+func release(conn *pgxpool.Conn) {
+	if conn != nil {
+		conn.Release()
+	}
+}
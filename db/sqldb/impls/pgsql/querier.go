@@ -0,0 +1,117 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier is implemented by *pgxpool.Pool, *pgxpool.Conn and pgx.Tx
+// so DBHandle and Tx share the same query paths
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// tracer reports the queries of a DBHandle or Tx to the client's Instrumentation
+type tracer struct {
+	inst *sqldb.Instrumentation
+	inTx bool
+}
+
+func (t tracer) begin(ctx context.Context, op sqldb.QueryOp, query string, args []any) (context.Context, func(int64, error)) {
+	return t.inst.Begin(ctx, sqldb.QueryEvent{Op: op, SQL: query, Args: args, InTx: t.inTx})
+}
+
+func exec(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Result, error) {
+	ctx, done := t.begin(ctx, sqldb.OpExec, query, args)
+	tag, err := q.Exec(ctx, query, args...)
+	err = convertErr(err)
+	done(tag.RowsAffected(), err)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{tag: tag}, nil
+}
+
+func queryRows(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Rows, error) {
+	ctx, done := t.begin(ctx, sqldb.OpQuery, query, args)
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		err = convertErr(err)
+		done(-1, err)
+		return nil, err
+	}
+	return &Rows{
+		conn:    nil, // pool or tx manages connection, no need to release here
+		current: rows,
+		batch:   nil, // single query, no batch
+		done:    done,
+	}, nil
+}
+
+func queryRow(ctx context.Context, q querier, t tracer, query string, args ...any) sqldb.Row {
+	ctx, done := t.begin(ctx, sqldb.OpQueryRow, query, args)
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		done(-1, convertErr(err))
+		return &Row{err: err}
+	}
+	return &Row{rows: rows, done: done}
+}
+
+func copyFrom(ctx context.Context, q querier, t tracer, table string, columns []string, rows [][]any) (int64, error) {
+	ctx, done := t.begin(ctx, sqldb.OpCopyFrom, table, nil)
+	src := pgx.CopyFromRows(rows)
+	count, err := q.CopyFrom(ctx, pgx.Identifier{table}, columns, src)
+	err = convertErr(err)
+	done(count, err)
+	return count, err
+}
+
+// insertStmt appends `RETURNING id` unless the query has a RETURNING clause.
+// For a multi-row INSERT, LastInsertId() is the first id, as in MySQL
+func insertStmt(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Result, error) {
+	trimmed := strings.TrimSpace(query)
+	if !strings.HasPrefix(strings.ToUpper(trimmed), "INSERT") {
+		return nil, fmt.Errorf("InsertStmt must start with INSERT")
+	}
+	if sqldb.HasReturningClause(query) {
+		return exec(ctx, q, t, query, args...)
+	}
+
+	rows, err := insertReturning(ctx, q, t, "id", query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var id, count int64
+	for rows.Next() {
+		if count == 0 {
+			if err = rows.Scan(&id); err != nil {
+				return nil, fmt.Errorf("failed to scan id (use sqldb.InsertKeys for other key columns or types). %w", err)
+			}
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return &Result{tag: pgconn.NewCommandTag("INSERT 0 " + strconv.FormatInt(count, 10)), lastInsertID: id}, nil
+}
+
+func insertReturning(ctx context.Context, q querier, t tracer, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	query, err := sqldb.CheckInsertReturning("pgsql", keyColumn, query)
+	if err != nil {
+		return nil, err
+	}
+	return queryRows(ctx, q, t, query+" RETURNING "+pgx.Identifier{keyColumn}.Sanitize(), args...)
+}
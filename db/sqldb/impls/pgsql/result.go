@@ -19,11 +19,11 @@ func (r *Result) RowsAffected() (int64, error) {
 	return r.tag.RowsAffected(), nil
 }
 
-// LastInsertId - PostgreSQL does not support LastInsertId.
+// LastInsertId - PostgreSQL does not support LastInsertId; only set by InsertStmt (first `id` of the INSERT).
 func (r *Result) LastInsertId() (int64, error) {
 	if r.lastInsertID != 0 {
 		return r.lastInsertID, nil
 	}
 	// err := dbHandle.QueryRow(ctx, "INSERT INTO users(first_name, last_name) VALUES($1, $2) RETURNING id", "John", "Doe").Scan(&id)
-	return 0, fmt.Errorf("LastInsertId not supported; use `RETURNING id` instead")
+	return 0, fmt.Errorf("LastInsertId not supported; use InsertStmt, sqldb.InsertKeys or `RETURNING id` instead")
 }
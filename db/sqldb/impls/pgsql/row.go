@@ -1,23 +1,50 @@
 package pgsql
 
 import (
-	"errors"
-
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
 	"github.com/jackc/pgx/v5"
 )
 
+// Row is lazy; a query error surfaces at Scan.
+// Built on pgx.Rows (as pgx.Row itself is) so column names are available
 type Row struct {
-	row pgx.Row
+	rows pgx.Rows
+	err  error
+	done func(int64, error) // reports the query to Instrumentation once Scan has read it
 }
 
 // Ensure pgsql.Row implements sqldb.Row interface
 var _ sqldb.Row = (*Row)(nil)
 
+func (r *Row) Columns() ([]string, error) {
+	if r.err != nil {
+		return nil, convertErr(r.err)
+	}
+	return fieldNames(r.rows.FieldDescriptions()), nil
+}
+
 func (r *Row) Scan(dest ...any) error {
-	err := r.row.Scan(dest...)
-	if errors.Is(err, pgx.ErrNoRows) {
+	if r.err != nil {
+		return convertErr(r.err)
+	}
+	defer r.close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return convertErr(err)
+		}
 		return sqldb.ErrNoRows
 	}
-	return err
+	if err := r.rows.Scan(dest...); err != nil {
+		return convertErr(err)
+	}
+	r.close()
+	return convertErr(r.rows.Err())
+}
+
+func (r *Row) close() {
+	r.rows.Close()
+	if r.done != nil {
+		r.done(r.rows.CommandTag().RowsAffected(), convertErr(r.rows.Err()))
+		r.done = nil
+	}
 }
@@ -1,34 +1,66 @@
 package pgsql
 
 import (
+	"math"
+	"strconv"
+	"strings"
+
 	"github.com/LearnLoop365/flxr-core/db/sqldb"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Rows struct {
-	conn    *pgxpool.Conn
-	current pgx.Rows
-	batch   pgx.BatchResults
+	conn      *pgxpool.Conn
+	current   pgx.Rows
+	batch     pgx.BatchResults
+	remaining int   // result sets left in batch
+	err       error // error while advancing result sets
+	// done reports the query to Instrumentation on Close: pgx runs it while the rows are read
+	done func(int64, error)
 }
 
 // Ensure pgsql.Rows implements sqldb.Rows
 var _ sqldb.Rows = (*Rows)(nil)
 
+func (r *Rows) Columns() ([]string, error) {
+	return fieldNames(r.current.FieldDescriptions()), nil
+}
+
+func (r *Rows) ColumnTypes() ([]sqldb.ColumnType, error) {
+	return columnTypes(r.current), nil
+}
+
 func (r *Rows) Next() bool {
 	return r.current.Next()
 }
 
 func (r *Rows) Scan(dest ...any) error {
-	return r.current.Scan(dest...)
+	return convertErr(r.current.Scan(dest...))
 }
 
 func (r *Rows) Close() error {
+	err := r.err
+	rowsAffected := int64(-1)
 	if r.current != nil {
 		r.current.Close()
+		if err == nil {
+			err = r.current.Err()
+		}
+		if r.batch == nil {
+			rowsAffected = r.current.CommandTag().RowsAffected()
+		}
 	}
 	if r.batch != nil {
-		_ = r.batch.Close()
+		if batchErr := r.batch.Close(); err == nil {
+			err = batchErr
+		}
+	}
+	if r.done != nil {
+		r.done(rowsAffected, convertErr(err))
+		r.done = nil
 	}
 	if r.conn != nil {
 		r.conn.Release()
@@ -37,18 +69,74 @@ func (r *Rows) Close() error {
 }
 
 func (r *Rows) Err() error {
-	return r.current.Err()
+	if r.err != nil {
+		return convertErr(r.err)
+	}
+	return convertErr(r.current.Err())
 }
 
 func (r *Rows) NextResultSet() bool {
-	if r.batch == nil {
+	if r.batch == nil || r.remaining == 0 || r.err != nil {
+		return false
+	}
+	// the current result must be fully read before the next one is available
+	r.current.Close()
+	if err := r.current.Err(); err != nil {
+		r.err = err
 		return false
 	}
 	nextRows, err := r.batch.Query()
+	r.remaining--
 	if err != nil {
-		// No more result sets or query failed
+		r.err = err
 		return false
 	}
 	r.current = nextRows
 	return true
 }
+
+func fieldNames(fields []pgconn.FieldDescription) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// columnTypes derives metadata from the RowDescription.
+// PostgreSQL doesn't report nullability of result columns, so HasNullable is false
+func columnTypes(rows pgx.Rows) []sqldb.ColumnType {
+	fields := rows.FieldDescriptions()
+	var typeMap *pgtype.Map
+	if conn := rows.Conn(); conn != nil {
+		typeMap = conn.TypeMap()
+	}
+	types := make([]sqldb.ColumnType, len(fields))
+	for i, f := range fields {
+		t := sqldb.ColumnType{Name: f.Name}
+		if typeMap != nil {
+			if pgType, ok := typeMap.TypeForOID(f.DataTypeOID); ok {
+				t.DatabaseType = strings.ToUpper(pgType.Name)
+			}
+		}
+		if t.DatabaseType == "" {
+			t.DatabaseType = strconv.FormatUint(uint64(f.DataTypeOID), 10) // unknown type, e.g. user-defined
+		}
+		// type modifiers include a 4-byte header (VARHDRSZ)
+		switch f.DataTypeOID {
+		case pgtype.VarcharOID, pgtype.BPCharOID:
+			if f.TypeModifier >= 4 {
+				t.Length, t.HasLength = int64(f.TypeModifier-4), true
+			}
+		case pgtype.TextOID, pgtype.ByteaOID:
+			t.Length, t.HasLength = math.MaxInt64, true // unbounded
+		case pgtype.NumericOID:
+			if f.TypeModifier >= 4 {
+				mod := f.TypeModifier - 4
+				t.Precision, t.Scale, t.HasPrecision = int64(mod>>16&0xffff), int64(mod&0xffff), true
+			}
+		}
+		types[i] = t
+	}
+	return types
+}
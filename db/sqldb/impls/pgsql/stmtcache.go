@@ -0,0 +1,73 @@
+package pgsql
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const defaultStmtCacheSize = 256
+
+// connStmtCaches tracks the stmts prepared on each pooled connection.
+// Stmts are prepared lazily, on the connection that runs them, under a name derived from the SQL,
+// so the same SQL maps to the same stmt on every connection and no connection is pinned.
+// A cache is dropped when the pool closes its connection (see Client.Init)
+type connStmtCaches struct {
+	capacity int
+	caches   sync.Map // *pgx.Conn -> *stmtCache
+}
+
+// stmtCache holds the stmt names prepared on one connection, least recently used at the back.
+// Only the goroutine holding the connection touches it
+type stmtCache struct {
+	lru   *list.List
+	elems map[string]*list.Element
+}
+
+func newConnStmtCaches(capacity int) *connStmtCaches {
+	if capacity <= 0 {
+		capacity = defaultStmtCacheSize
+	}
+	return &connStmtCaches{capacity: capacity}
+}
+
+// stmtName is deterministic, unlike time based names which can collide
+func stmtName(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return "sqldb_" + hex.EncodeToString(sum[:12])
+}
+
+// prepare makes sure sql is prepared on conn and returns the stmt name.
+// Beyond capacity, the least recently used stmt of conn is deallocated
+func (c *connStmtCaches) prepare(ctx context.Context, conn *pgx.Conn, sql string) (string, error) {
+	name := stmtName(sql)
+	v, _ := c.caches.LoadOrStore(conn, &stmtCache{lru: list.New(), elems: make(map[string]*list.Element)})
+	cache := v.(*stmtCache)
+	if elem, exists := cache.elems[name]; exists {
+		cache.lru.MoveToFront(elem)
+		return name, nil
+	}
+
+	if _, err := conn.Prepare(ctx, name, sql); err != nil {
+		return "", err
+	}
+	cache.elems[name] = cache.lru.PushFront(name)
+	for cache.lru.Len() > c.capacity {
+		oldest := cache.lru.Remove(cache.lru.Back()).(string)
+		delete(cache.elems, oldest)
+		if err := conn.Deallocate(ctx, oldest); err != nil {
+			return "", fmt.Errorf("failed to deallocate evicted stmt: %w", err)
+		}
+	}
+	return name, nil
+}
+
+// forget drops the cache of a closed connection
+func (c *connStmtCaches) forget(conn *pgx.Conn) {
+	c.caches.Delete(conn)
+}
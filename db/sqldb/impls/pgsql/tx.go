@@ -8,36 +8,83 @@ import (
 )
 
 type Tx struct {
-	tx pgx.Tx
+	tx     pgx.Tx
+	caches *connStmtCaches
+	inst   *sqldb.Instrumentation
 }
 
 // Ensure pgsql.Tx implements sqldb.Tx
 var _ sqldb.Tx = (*Tx)(nil)
 
 func (t *Tx) Commit(ctx context.Context) error {
-	return t.tx.Commit(ctx)
+	ctx, done := t.tracer().begin(ctx, sqldb.OpCommit, "", nil)
+	err := convertErr(t.tx.Commit(ctx))
+	done(-1, err)
+	return err
 }
 
 func (t *Tx) Rollback(ctx context.Context) error {
-	return t.tx.Rollback(ctx)
+	ctx, done := t.tracer().begin(ctx, sqldb.OpRollback, "", nil)
+	err := convertErr(t.tx.Rollback(ctx))
+	done(-1, err)
+	return err
+}
+
+func (t *Tx) tracer() tracer {
+	return tracer{inst: t.inst, inTx: true}
 }
 
 func (t *Tx) Exec(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
-	tag, err := t.tx.Exec(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	return &Result{tag: tag}, nil
+	return exec(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) QueryRows(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
+	return queryRows(ctx, t.tx, t.tracer(), query, args...)
 }
 
 func (t *Tx) Query(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
-	rows, err := t.tx.Query(ctx, query, args...)
-	if err != nil {
-		return nil, err
+	return t.QueryRows(ctx, query, args...)
+}
+
+func (t *Tx) QueryRow(ctx context.Context, query string, args ...any) sqldb.Row {
+	return queryRow(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) SendBatch(ctx context.Context, batch *sqldb.Batch) (sqldb.Rows, error) {
+	return sendBatch(ctx, t.tx, t.tracer(), batch)
+}
+
+func (t *Tx) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	return copyFrom(ctx, t.tx, t.tracer(), table, columns, rows)
+}
+
+func (t *Tx) InsertStmt(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
+	return insertStmt(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) InsertReturning(ctx context.Context, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	return insertReturning(ctx, t.tx, t.tracer(), keyColumn, query, args...)
+}
+
+// Prepare - the tx already owns its connection, so the stmt is prepared there
+func (t *Tx) Prepare(ctx context.Context, query string) (sqldb.PreparedStmt, error) {
+	if _, err := t.caches.prepare(ctx, t.tx.Conn(), query); err != nil {
+		return nil, convertErr(err)
 	}
-	return &Rows{
-		conn:    nil, // tx already owns the connection
-		current: rows,
-		batch:   nil,
-	}, nil
+	return &PreparedStmt{tx: t.tx, caches: t.caches, inst: t.inst, sql: query}, nil
+}
+
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	_, err := t.tx.Exec(ctx, "SAVEPOINT "+pgx.Identifier{name}.Sanitize())
+	return convertErr(err)
+}
+
+func (t *Tx) RollbackTo(ctx context.Context, name string) error {
+	_, err := t.tx.Exec(ctx, "ROLLBACK TO SAVEPOINT "+pgx.Identifier{name}.Sanitize())
+	return convertErr(err)
+}
+
+func (t *Tx) Release(ctx context.Context, name string) error {
+	_, err := t.tx.Exec(ctx, "RELEASE SAVEPOINT "+pgx.Identifier{name}.Sanitize())
+	return convertErr(err)
 }
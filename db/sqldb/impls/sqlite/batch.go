@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+// sendBatch - SQLite is in-process, so there is no round trip to save.
+// Queries run one by one as the caller advances with Rows.NextResultSet(); Rows.Close() runs the rest
+func sendBatch(ctx context.Context, q querier, t tracer, batch *sqldb.Batch) (sqldb.Rows, error) {
+	if batch == nil || batch.Len() == 0 {
+		return nil, fmt.Errorf("empty batch")
+	}
+	first := batch.Queries[0]
+	var batchSQL string
+	if t.inst.Enabled() {
+		batchSQL = batch.SQL()
+	}
+	// reports the first query only; the others run as the caller advances
+	queryCtx, done := t.begin(ctx, sqldb.OpBatch, batchSQL, nil)
+	rows, err := q.QueryContext(queryCtx, first.SQL, first.Args...)
+	err = convertErr(err)
+	done(-1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{
+		rows:    rows,
+		ctx:     ctx,
+		querier: q,
+		pending: batch.Queries[1:],
+	}, nil
+}
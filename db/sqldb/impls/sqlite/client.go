@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	_ "modernc.org/sqlite" // side-effect; pure Go, no cgo required
+)
+
+type Client struct {
+	//sqldb.Client // [Embedded Interface]
+
+	Conf *sqldb.Conf
+	// Instrumentation runs hooks around queries (tracing, metrics, slow query log). optional
+	Instrumentation *sqldb.Instrumentation
+
+	// db fields are implementation details, not exported
+	db  *sql.DB
+	dsn string
+}
+
+// Ensure sqlite.Client implements sqldb.Client interface
+var _ sqldb.Client = (*Client)(nil)
+
+// Init opens the database file given by Conf.DB (or ":memory:").
+// Host, Port, User, PW, TLS, AppName are not used by SQLite.
+func (c *Client) Init() error {
+	var err error
+	driver := c.Conf.Driver
+	if driver == "" {
+		driver = "sqlite"
+	}
+	c.dsn = c.buildDSN()
+	if c.db, err = sql.Open(driver, c.dsn); err != nil {
+		return err
+	}
+	if c.isMemory() {
+		// each connection to ":memory:" is a separate database -> pin to a single connection
+		c.db.SetMaxOpenConns(1)
+		c.db.SetMaxIdleConns(1)
+		c.db.SetConnMaxLifetime(0)
+	} else {
+		// Pool tuning (sqldb.Conf.Pool, defaults if not set)
+		pool := c.Conf.Pool
+		maxConns := orInt(pool.MaxConns, 10)
+		c.db.SetConnMaxLifetime(pool.MaxConnLifetime.Or(3 * time.Minute))
+		c.db.SetMaxOpenConns(maxConns)
+		c.db.SetMaxIdleConns(orInt(pool.MaxIdleConns, maxConns))
+		if pool.MaxConnIdleTime > 0 {
+			c.db.SetConnMaxIdleTime(time.Duration(pool.MaxConnIdleTime))
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.Conf.ConnectTimeout.Or(5*time.Second))
+	defer cancel()
+	if err = c.db.PingContext(ctx); err != nil {
+		_ = c.db.Close() // don't leak the *sql.DB; Init may be retried
+		c.db = nil
+		return err
+	}
+	log.Printf("[INFO] sqlite db initialized (dsn=%s)", c.dsn)
+	return nil
+}
+
+func (c *Client) buildDSN() string {
+	path := c.Conf.DB
+	if path == "" {
+		path = ":memory:"
+	}
+	// foreign keys are off by default in SQLite
+	// busy_timeout avoids immediate SQLITE_BUSY under concurrent writers
+	pragmas := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	if len(c.Conf.Params) > 0 {
+		// extra driver params e.g. "_txlock": "immediate"
+		params := url.Values{}
+		for k, v := range c.Conf.Params {
+			params.Set(k, v)
+		}
+		pragmas += "&" + params.Encode()
+	}
+	if strings.Contains(path, "?") {
+		return path + "&" + pragmas
+	}
+	return path + "?" + pragmas
+}
+
+func orInt(n int, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+func (c *Client) isMemory() bool {
+	return c.Conf.DB == "" || strings.HasPrefix(c.Conf.DB, ":memory:") || strings.Contains(c.Conf.DB, "mode=memory")
+}
+
+func (c *Client) Close() error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+func (c *Client) DBHandle() sqldb.DBHandle {
+	return &DBHandle{db: c.db, inst: c.Instrumentation}
+}
+
+// BeginTx - SQLite transactions are always SERIALIZABLE, which satisfies any requested IsoLevel.
+// TxOptions.Deferrable is ignored (PostgreSQL only)
+func (c *Client) BeginTx(ctx context.Context, opts *sqldb.TxOptions) (sqldb.Tx, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("sqlite client not initialized")
+	}
+	if opts == nil || !opts.ReadOnly {
+		tx, err := c.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, convertErr(err)
+		}
+		return &Tx{tx: tx, inst: c.Instrumentation}, nil
+	}
+
+	// the driver doesn't enforce read-only; pin a connection and set `query_only` for the tx lifetime
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		_, _ = conn.ExecContext(context.Background(), "PRAGMA query_only = OFF")
+		_ = conn.Close()
+		return nil, err
+	}
+	return &Tx{tx: tx, readOnlyConn: conn, inst: c.Instrumentation}, nil
+}
@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+type DBHandle struct {
+	// sqldb.DBHandle // [Interface]
+
+	db   *sql.DB
+	inst *sqldb.Instrumentation
+}
+
+// Ensure sqlite.DBHandle implements sqldb.DBHandle interface
+var _ sqldb.DBHandle = (*DBHandle)(nil)
+
+func (h *DBHandle) Exec(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
+	return exec(ctx, h.db, tracer{inst: h.inst}, query, args...)
+}
+
+func (h *DBHandle) QueryRows(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
+	return queryRows(ctx, h.db, tracer{inst: h.inst}, query, args...)
+}
+
+func (h *DBHandle) QueryRow(ctx context.Context, query string, args ...any) sqldb.Row {
+	return queryRow(ctx, h.db, tracer{inst: h.inst}, query, args...)
+}
+
+func (h *DBHandle) SendBatch(ctx context.Context, batch *sqldb.Batch) (sqldb.Rows, error) {
+	return sendBatch(ctx, h.db, tracer{inst: h.inst}, batch)
+}
+
+// CopyFrom - SQLite doesn't have native COPY.
+// Emulated by a single prepared INSERT executed per row inside one transaction,
+// which is the fastest bulk-load path for SQLite (one journal sync at commit).
+func (h *DBHandle) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	ctx, done := tracer{inst: h.inst}.begin(ctx, sqldb.OpCopyFrom, table, nil)
+	count, err := h.copyFrom(ctx, table, columns, rows)
+	done(count, err)
+	return count, err
+}
+
+func (h *DBHandle) copyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, convertErr(err)
+	}
+	count, err := copyFromTx(ctx, tx, table, columns, rows)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, convertErr(err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, convertErr(err)
+	}
+	return count, nil
+}
+
+// Listen - param: channel
+func (h *DBHandle) Listen(_ context.Context, _ string) (<-chan sqldb.Notification, error) {
+	return nil, fmt.Errorf("method `Listen` not supported for SQLite")
+}
+
+// Notify - SQLite is embedded, there are no other sessions to notify.
+// Publish in-process (e.g. Go channels) instead
+func (h *DBHandle) Notify(_ context.Context, _ string, _ string) error {
+	return fmt.Errorf("method `Notify` not supported for SQLite")
+}
+
+func (h *DBHandle) InsertStmt(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
+	return insertStmt(ctx, h.db, tracer{inst: h.inst}, query, args...)
+}
+
+func (h *DBHandle) InsertReturning(ctx context.Context, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	return insertReturning(ctx, h.db, tracer{inst: h.inst}, keyColumn, query, args...)
+}
+
+func (h *DBHandle) Prepare(ctx context.Context, query string) (sqldb.PreparedStmt, error) {
+	return prepare(ctx, h.db, tracer{inst: h.inst}, query)
+}
+
+// ValidateStmt compiles query without running it (see sqldb.StmtValidator)
+func (h *DBHandle) ValidateStmt(ctx context.Context, query string) error {
+	return validateStmt(ctx, h.db, query)
+}
+
+func copyFromTx(ctx context.Context, tx *sql.Tx, table string, columns []string, rows [][]any) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("CopyFrom requires at least one column")
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	quotedCols := make([]string, len(columns))
+	for i, col := range columns {
+		quotedCols[i] = quoteIdent(col)
+	}
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		quoteIdent(table),
+		strings.Join(quotedCols, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "),
+	)
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var count int64
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return count, fmt.Errorf("row %d has %d values, expected %d", i, len(row), len(columns))
+		}
+		if _, err = stmt.ExecContext(ctx, row...); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// quoteIdent quotes an identifier with double quotes, escaping embedded quotes
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
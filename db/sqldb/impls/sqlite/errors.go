@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strconv"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Ensure sqlite.Client implements sqldb.RetryClassifier
+var _ sqldb.RetryClassifier = (*Client)(nil)
+
+// IsRetryable - SQLITE_BUSY, SQLITE_LOCKED (incl. extended codes) after busy_timeout ran out
+func (c *Client) IsRetryable(err error) bool {
+	var liteErr *sqlite.Error
+	if !errors.As(err, &liteErr) {
+		return false
+	}
+	switch liteErr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
+
+var (
+	// UNIQUE constraint failed: t.col | NOT NULL constraint failed: t.col (first column only)
+	reTableColumn = regexp.MustCompile(`constraint failed: ([^.\s]+)\.([^,\s(]+)`)
+	// CHECK constraint failed: chk_name
+	reCheck = regexp.MustCompile(`CHECK constraint failed: ([^\s(]+)`)
+)
+
+// convertErr classifies a modernc.org/sqlite error into *sqldb.Error.
+// Unclassified errors are returned as-is
+func convertErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return sqldb.ErrNoRows
+	}
+	var sqlErr *sqldb.Error
+	if errors.As(err, &sqlErr) {
+		return err // already converted
+	}
+
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) {
+		e := &sqldb.Error{Code: strconv.Itoa(liteErr.Code()), Err: err}
+		msg := liteErr.Error()
+		switch liteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			e.Kind = sqldb.ErrUniqueViolation
+			if m := reTableColumn.FindStringSubmatch(msg); m != nil {
+				e.Table, e.Column = m[1], m[2]
+			}
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			e.Kind = sqldb.ErrForeignKeyViolation // SQLite doesn't report which one
+		case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+			e.Kind = sqldb.ErrNotNullViolation
+			if m := reTableColumn.FindStringSubmatch(msg); m != nil {
+				e.Table, e.Column = m[1], m[2]
+			}
+		case sqlite3.SQLITE_CONSTRAINT_CHECK:
+			e.Kind = sqldb.ErrCheckViolation
+			if m := reCheck.FindStringSubmatch(msg); m != nil {
+				e.Constraint = m[1]
+			}
+		case sqlite3.SQLITE_INTERRUPT:
+			e.Kind = sqldb.ErrQueryCanceled
+		default:
+			return err
+		}
+		return e
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return &sqldb.Error{Kind: sqldb.ErrQueryCanceled, Err: err}
+	}
+	return err
+}
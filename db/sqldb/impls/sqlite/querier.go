@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+// querier is implemented by *sql.DB and *sql.Tx
+// so DBHandle and Tx share the same query paths
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// tracer reports the queries of a DBHandle or Tx to the client's Instrumentation
+type tracer struct {
+	inst *sqldb.Instrumentation
+	inTx bool
+}
+
+func (t tracer) begin(ctx context.Context, op sqldb.QueryOp, query string, args []any) (context.Context, func(int64, error)) {
+	return t.inst.Begin(ctx, sqldb.QueryEvent{Op: op, SQL: query, Args: args, InTx: t.inTx})
+}
+
+func exec(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Result, error) {
+	ctx, done := t.begin(ctx, sqldb.OpExec, query, args)
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		err = convertErr(err)
+		done(-1, err)
+		return nil, err
+	}
+	r := &Result{result: result}
+	done(sqldb.RowsAffectedOf(r), nil)
+	return r, nil
+}
+
+func queryRows(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Rows, error) {
+	ctx, done := t.begin(ctx, sqldb.OpQuery, query, args)
+	rows, err := q.QueryContext(ctx, query, args...)
+	err = convertErr(err)
+	done(-1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{rows: rows}, nil
+}
+
+func queryRow(ctx context.Context, q querier, t tracer, query string, args ...any) sqldb.Row {
+	ctx, done := t.begin(ctx, sqldb.OpQueryRow, query, args)
+	rows, err := q.QueryContext(ctx, query, args...)
+	done(-1, convertErr(err))
+	return &Row{rows: rows, err: err}
+}
+
+// insertStmt - Result.LastInsertId() is derived from last_insert_rowid() of the connection that ran the INSERT:
+// for a multi-row INSERT it is the first rowid, as in MySQL (rowids of one INSERT are consecutive)
+func insertStmt(ctx context.Context, q querier, t tracer, query string, args ...any) (sqldb.Result, error) {
+	trimmed := strings.TrimSpace(query)
+	if !strings.HasPrefix(strings.ToUpper(trimmed), "INSERT") {
+		return nil, fmt.Errorf("InsertStmt must start with INSERT")
+	}
+	result, err := exec(ctx, q, t, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	r := result.(*Result)
+	r.insert = true
+	r.upsert = sqldb.IsUpsert("sqlite", query)
+	return r, nil
+}
+
+func insertReturning(ctx context.Context, q querier, t tracer, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	query, err := sqldb.CheckInsertReturning("sqlite", keyColumn, query)
+	if err != nil {
+		return nil, err
+	}
+	return queryRows(ctx, q, t, query+` RETURNING "`+strings.ReplaceAll(keyColumn, `"`, `""`)+`"`, args...)
+}
+
+func prepare(ctx context.Context, q querier, t tracer, query string) (sqldb.PreparedStmt, error) {
+	stmt, err := q.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return &PreparedStmt{stmt: stmt, sql: query, tracer: t}, nil
+}
+
+// validateStmt compiles query with EXPLAIN without running it, since the driver compiles stmts lazily
+// (Prepare doesn't surface errors). NULL args fill the positional params;
+// stmts with named params can't be filled and are not checked
+func validateStmt(ctx context.Context, q querier, query string) error {
+	if _, named, err := sqldb.CompileNamedPlaceholders(query, 0); err != nil || named != nil {
+		return nil
+	}
+	rows, err := q.QueryContext(ctx, "EXPLAIN "+query, make([]any, maxParamIndex(query))...)
+	if err != nil {
+		return convertErr(err)
+	}
+	return convertErr(rows.Close())
+}
+
+// maxParamIndex returns an upper bound of the positional param count of query:
+// `?` takes the largest index so far + 1 and `?NNN` takes NNN. `?` in literals only overcount, which is harmless
+func maxParamIndex(query string) int {
+	maxIndex := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] != '?' {
+			continue
+		}
+		j := i + 1
+		for j < len(query) && '0' <= query[j] && query[j] <= '9' {
+			j++
+		}
+		if j == i+1 {
+			maxIndex++
+			continue
+		}
+		if n, err := strconv.Atoi(query[i+1 : j]); err == nil {
+			maxIndex = max(maxIndex, n)
+		}
+		i = j - 1
+	}
+	return maxIndex
+}
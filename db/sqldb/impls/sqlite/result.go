@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+type Result struct {
+	result sql.Result
+	insert bool // by InsertStmt
+	upsert bool // ON CONFLICT, OR IGNORE or OR REPLACE: changes() may count rows that were not inserted
+}
+
+// Ensure sqlite.Result implements sqldb.Result interface
+var _ sqldb.Result = (*Result)(nil)
+
+func (r *Result) RowsAffected() (int64, error) {
+	return r.result.RowsAffected()
+}
+
+// LastInsertId returns last_insert_rowid() captured right after the statement.
+// From InsertStmt: the first rowid of the INSERT, as in MySQL; 0 if no row was inserted.
+// Fails for an upsert that changed rows, as the first rowid can't be derived from them
+func (r *Result) LastInsertId() (int64, error) {
+	lastID, err := r.result.LastInsertId()
+	if err != nil || !r.insert {
+		return lastID, err
+	}
+	count, err := r.result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil // last_insert_rowid() is of an earlier INSERT
+	}
+	if r.upsert {
+		return 0, fmt.Errorf("LastInsertId is not supported for upserts, OR IGNORE or OR REPLACE; use sqldb.InsertKeys")
+	}
+	return lastID - count + 1, nil
+}
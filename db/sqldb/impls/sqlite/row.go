@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+// Row is lazy; a query error surfaces at Scan.
+// Built on *sql.Rows (as *sql.Row itself is) so column names are available
+type Row struct {
+	rows *sql.Rows
+	err  error
+}
+
+// Ensure sqlite.Row implements sqldb.Row interface
+var _ sqldb.Row = (*Row)(nil)
+
+func (r *Row) Columns() ([]string, error) {
+	if r.err != nil {
+		return nil, convertErr(r.err)
+	}
+	return r.rows.Columns()
+}
+
+func (r *Row) Scan(dest ...any) error {
+	if r.err != nil {
+		return convertErr(r.err)
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return convertErr(err)
+		}
+		return sqldb.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return convertErr(err)
+	}
+	return convertErr(r.rows.Close())
+}
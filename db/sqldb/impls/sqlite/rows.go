@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+type Rows struct {
+	rows *sql.Rows
+
+	// batch only: remaining queries, run by NextResultSet or else by Close
+	ctx     context.Context
+	querier querier
+	pending []sqldb.BatchQuery
+	err     error
+}
+
+// Ensure sqlite.Rows implements sqldb.Rows interface
+var _ sqldb.Rows = (*Rows)(nil)
+
+func (r *Rows) Columns() ([]string, error) {
+	return r.rows.Columns()
+}
+
+func (r *Rows) ColumnTypes() ([]sqldb.ColumnType, error) {
+	colTypes, err := r.rows.ColumnTypes()
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return columnTypes(colTypes), nil
+}
+
+func (r *Rows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r *Rows) Scan(dest ...any) error {
+	return convertErr(r.rows.Scan(dest...))
+}
+
+// Close runs the batch queries the caller didn't advance to, stopping at the first error as NextResultSet does
+func (r *Rows) Close() error {
+	err := r.rows.Close()
+	pending := r.pending
+	r.pending = nil
+	for _, next := range pending {
+		if err != nil || r.err != nil {
+			break
+		}
+		var rows *sql.Rows
+		if rows, err = r.querier.QueryContext(r.ctx, next.SQL, next.Args...); err == nil {
+			err = rows.Close()
+		}
+	}
+	return convertErr(err)
+}
+
+func (r *Rows) NextResultSet() bool {
+	if len(r.pending) == 0 || r.err != nil {
+		return false
+	}
+	if err := r.rows.Close(); err != nil {
+		r.err = err
+		return false
+	}
+	next := r.pending[0]
+	r.pending = r.pending[1:]
+	rows, err := r.querier.QueryContext(r.ctx, next.SQL, next.Args...)
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.rows = rows
+	return true
+}
+
+func (r *Rows) Err() error {
+	if r.err != nil {
+		return convertErr(r.err)
+	}
+	return convertErr(r.rows.Err())
+}
+
+// columnTypes - SQLite reports the declared type as written, e.g. "VARCHAR(20)", "DECIMAL(10,2)";
+// the size is split off into Length / Precision & Scale. Nullability isn't reported for result columns
+func columnTypes(colTypes []*sql.ColumnType) []sqldb.ColumnType {
+	types := make([]sqldb.ColumnType, len(colTypes))
+	for i, ct := range colTypes {
+		t := sqldb.ColumnType{Name: ct.Name()}
+		declared := strings.ToUpper(strings.TrimSpace(ct.DatabaseTypeName()))
+		base, size, hasSize := strings.Cut(declared, "(")
+		t.DatabaseType = strings.TrimSpace(base)
+		if hasSize {
+			size = strings.TrimSuffix(strings.TrimSpace(size), ")")
+			p, s, isDecimal := strings.Cut(size, ",")
+			n, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+			if err == nil {
+				if isDecimal || strings.Contains(t.DatabaseType, "DEC") || strings.Contains(t.DatabaseType, "NUMERIC") {
+					t.Precision, t.HasPrecision = n, true
+					t.Scale, _ = strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+				} else {
+					t.Length, t.HasLength = n, true
+				}
+			}
+		}
+		types[i] = t
+	}
+	return types
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+type Tx struct {
+	tx           *sql.Tx
+	readOnlyConn *sql.Conn // pinned with `PRAGMA query_only` for read-only tx. nil otherwise
+	inst         *sqldb.Instrumentation
+}
+
+// Ensure sqlite.Tx implements sqldb.Tx interface
+var _ sqldb.Tx = (*Tx)(nil)
+
+func (t *Tx) Commit(ctx context.Context) error {
+	defer t.releaseConn()
+	_, done := t.tracer().begin(ctx, sqldb.OpCommit, "", nil)
+	err := convertErr(t.tx.Commit())
+	done(-1, err)
+	return err
+}
+
+func (t *Tx) Rollback(ctx context.Context) error {
+	defer t.releaseConn()
+	_, done := t.tracer().begin(ctx, sqldb.OpRollback, "", nil)
+	err := convertErr(t.tx.Rollback())
+	done(-1, err)
+	return err
+}
+
+func (t *Tx) tracer() tracer {
+	return tracer{inst: t.inst, inTx: true}
+}
+
+// releaseConn resets `query_only` before returning the pinned connection to the pool
+func (t *Tx) releaseConn() {
+	if t.readOnlyConn == nil {
+		return
+	}
+	if _, err := t.readOnlyConn.ExecContext(context.Background(), "PRAGMA query_only = OFF"); err != nil {
+		// don't hand a read-only connection back to the pool
+		_ = t.readOnlyConn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	_ = t.readOnlyConn.Close()
+	t.readOnlyConn = nil
+}
+
+func (t *Tx) Exec(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
+	return exec(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) QueryRows(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
+	return queryRows(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) Query(ctx context.Context, query string, args ...any) (sqldb.Rows, error) {
+	return t.QueryRows(ctx, query, args...)
+}
+
+func (t *Tx) QueryRow(ctx context.Context, query string, args ...any) sqldb.Row {
+	return queryRow(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) SendBatch(ctx context.Context, batch *sqldb.Batch) (sqldb.Rows, error) {
+	return sendBatch(ctx, t.tx, t.tracer(), batch)
+}
+
+func (t *Tx) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	ctx, done := t.tracer().begin(ctx, sqldb.OpCopyFrom, table, nil)
+	count, err := copyFromTx(ctx, t.tx, table, columns, rows)
+	err = convertErr(err)
+	done(count, err)
+	return count, err
+}
+
+func (t *Tx) InsertStmt(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
+	return insertStmt(ctx, t.tx, t.tracer(), query, args...)
+}
+
+func (t *Tx) InsertReturning(ctx context.Context, keyColumn string, query string, args ...any) (sqldb.Rows, error) {
+	return insertReturning(ctx, t.tx, t.tracer(), keyColumn, query, args...)
+}
+
+func (t *Tx) Prepare(ctx context.Context, query string) (sqldb.PreparedStmt, error) {
+	return prepare(ctx, t.tx, t.tracer(), query)
+}
+
+// ValidateStmt compiles query without running it (see sqldb.StmtValidator)
+func (t *Tx) ValidateStmt(ctx context.Context, query string) error {
+	return validateStmt(ctx, t.tx, query)
+}
+
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, "SAVEPOINT "+quoteIdent(name))
+	return convertErr(err)
+}
+
+func (t *Tx) RollbackTo(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+quoteIdent(name))
+	return convertErr(err)
+}
+
+func (t *Tx) Release(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+quoteIdent(name))
+	return convertErr(err)
+}
@@ -0,0 +1,155 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// InsertKeys runs a single INSERT (one or more VALUES rows) and returns the generated keyColumn of each row,
+// in insertion order, e.g. ids, err := sqldb.InsertKeys[uuid.UUID](ctx, tx, "user_id", query, args...)
+func InsertKeys[K any](ctx context.Context, q Queryer, keyColumn string, query string, args ...any) ([]K, error) {
+	rows, err := q.InsertReturning(ctx, keyColumn, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []K
+	for rows.Next() {
+		var key K
+		if err = rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan key %q. %w", keyColumn, err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during iterating rows. %w", err)
+	}
+	return keys, nil
+}
+
+// CheckInsertReturning is the common validation of InsertReturning args for impls.
+// Returns query without its trailing `;` and comments, ready for appending `RETURNING keyColumn`.
+// mysql derives keys from LastInsertId, stepping by @@auto_increment_increment, which is wrong for
+//   - rows skipped or updated by INSERT IGNORE and ON DUPLICATE KEY UPDATE
+//   - INSERT ... SELECT, a bulk insert whose ids are not consecutive with innodb_autoinc_lock_mode=2
+//   - rows with an explicit keyColumn value
+//
+// so those are rejected, as is an INSERT without a column list
+func CheckInsertReturning(dbtype string, keyColumn string, query string) (string, error) {
+	if keyColumn == "" {
+		return "", fmt.Errorf("InsertReturning requires a key column")
+	}
+	prefix := PlaceholderPrefixForDBType[dbtype]
+	query, err := TrimStatementEnd(query, prefix)
+	if err != nil {
+		return "", fmt.Errorf("InsertReturning: %w", err)
+	}
+	words := codeKeywords(query, prefix)
+	if len(words) == 0 || words[0] != "INSERT" {
+		return "", fmt.Errorf("InsertReturning must start with INSERT")
+	}
+	if slices.Contains(words, "RETURNING") {
+		return "", fmt.Errorf("InsertReturning query already has RETURNING; use QueryRows")
+	}
+	if dbtype != "mysql" {
+		return query, nil
+	}
+	if IsUpsert(dbtype, query) {
+		return "", fmt.Errorf("InsertReturning does not support INSERT IGNORE or ON DUPLICATE KEY UPDATE on mysql")
+	}
+	if slices.Contains(words, "SELECT") {
+		return "", fmt.Errorf("InsertReturning does not support INSERT ... SELECT or subqueries on mysql")
+	}
+	columns, ok := insertColumns(query, prefix)
+	if !ok {
+		return "", fmt.Errorf("InsertReturning on mysql requires a column list")
+	}
+	if slices.ContainsFunc(columns, func(column string) bool { return strings.EqualFold(column, keyColumn) }) {
+		return "", fmt.Errorf("InsertReturning on mysql does not support explicit values for %q", keyColumn)
+	}
+	return query, nil
+}
+
+// IsUpsert reports whether an INSERT may update or skip rows instead of inserting them:
+// ON CONFLICT, ON DUPLICATE KEY UPDATE, INSERT IGNORE and INSERT OR IGNORE / OR REPLACE
+func IsUpsert(dbtype string, query string) bool {
+	words := codeKeywords(query, PlaceholderPrefixForDBType[dbtype])
+	for i := 1; i < len(words); i++ {
+		switch words[i] {
+		case "IGNORE":
+			switch words[i-1] {
+			case "INSERT", "OR", "LOW_PRIORITY", "DELAYED", "HIGH_PRIORITY":
+				return true
+			}
+		case "REPLACE":
+			if i == 2 && words[1] == "OR" {
+				return true
+			}
+		case "CONFLICT", "DUPLICATE":
+			if words[i-1] == "ON" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// insertColumns returns the unquoted column names of `INSERT INTO t (a, b) VALUES ...`
+// or `INSERT INTO t SET a = ?, b = ?`. ok is false without a column list
+func insertColumns(sql string, prefix byte) (columns []string, ok bool) {
+	listDepth := 0 // 1 for the column list in (), 0 for SET assignments
+	from, depth := -1, 0
+	add := func(end int) {
+		column := sql[from:end]
+		if listDepth == 0 {
+			column, _, _ = strings.Cut(column, "=")
+		}
+		column = strings.TrimSpace(column)
+		column = column[strings.LastIndexByte(column, '.')+1:]
+		columns = append(columns, strings.Trim(column, "`\""))
+	}
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, prefix); j > i {
+			i = j
+			continue
+		}
+		switch c := sql[i]; {
+		case depth == 0 && from < 0 && isIdentStart(c) && (i == 0 || !isIdentByte(sql[i-1])):
+			j := i + 1
+			for j < len(sql) && isIdentByte(sql[j]) {
+				j++
+			}
+			switch strings.ToUpper(sql[i:j]) {
+			case "VALUES", "VALUE", "SELECT", "TABLE":
+				return nil, false
+			case "SET":
+				from = j
+			}
+			i = j
+			continue
+		case c == '(':
+			depth++
+			if depth == 1 && from < 0 {
+				listDepth = 1
+				from = i + 1
+			}
+		case c == ')':
+			depth--
+			if depth == 0 && listDepth == 1 {
+				add(i)
+				return columns, true
+			}
+		case c == ',' && from >= 0 && depth == listDepth:
+			add(i)
+			from = i + 1
+		}
+		i++
+	}
+	if from >= 0 && listDepth == 0 {
+		add(len(sql))
+		return columns, true
+	}
+	return nil, false
+}
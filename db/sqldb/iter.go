@@ -0,0 +1,87 @@
+package sqldb
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// RowsSeq iterates rows without accumulating them, e.g. to stream large results:
+//
+//	for item, err := range sqldb.RowsSeq(rows, fieldPtrsFromItem) {
+//		if err != nil { return err }
+//		...
+//	}
+//
+// A scan or iteration error is yielded once with the zero T, ending the sequence.
+// Closes rows when the sequence ends or the loop breaks. Single use
+func RowsSeq[T any](
+	rows Rows,
+	fieldPtrsFromItem func(*T) []any, // taking &item, returns []any{&item.Field1, ..., &item.FieldN}
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+		for rows.Next() {
+			var item T
+			if err := rows.Scan(fieldPtrsFromItem(&item)...); err != nil {
+				var zero T
+				yield(zero, fmt.Errorf("scan failed. %w", err))
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			var zero T
+			yield(zero, fmt.Errorf("error during iterating rows. %w", err))
+		}
+	}
+}
+
+// StructsSeq is RowsSeq scanning by `db` tags (see RowsToStructs)
+func StructsSeq[T any](rows Rows, mode ScanMode) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+		var zero T
+		fieldPtrsFromItem, err := structFieldPtrsFunc[T](rows, mode)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		for rows.Next() {
+			var item T
+			ptrs, err := fieldPtrsFromItem(&item)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if err = rows.Scan(ptrs...); err != nil {
+				yield(zero, fmt.Errorf("scan failed. %w", err))
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err = rows.Err(); err != nil {
+			yield(zero, fmt.Errorf("error during iterating rows. %w", err))
+		}
+	}
+}
+
+// structFieldPtrsFunc returns a func giving the scan destinations of an item for the columns of rows
+func structFieldPtrsFunc[T any](rows Rows, mode ScanMode) (func(*T) ([]any, error), error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct scanning requires a struct type, got %s", t)
+	}
+	fields := fieldsOf(t)
+	return func(item *T) ([]any, error) {
+		return fieldPtrs(reflect.ValueOf(item).Elem(), columns, fields, mode)
+	}, nil
+}
@@ -0,0 +1,125 @@
+package sqldb
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// DefaultDurationBuckets are the histogram upper bounds in seconds
+var DefaultDurationBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricsCollector is a QueryHook counting queries per op, exposed in the Prometheus text format:
+//   - <namespace>_queries_total{op,status="ok"|"error"}
+//   - <namespace>_query_duration_seconds{op} histogram
+//   - <namespace>_rows_affected_total{op}
+type MetricsCollector struct {
+	Namespace string    // default: "sqldb"
+	Buckets   []float64 // default: DefaultDurationBuckets. set before use
+
+	mu  sync.Mutex
+	ops map[QueryOp]*opMetrics
+}
+
+type opMetrics struct {
+	ok, errors   uint64
+	rowsAffected uint64
+	durationSum  float64
+	buckets      []uint64 // cumulative counts are computed on write
+}
+
+var _ QueryHook = (*MetricsCollector)(nil)
+var _ http.Handler = (*MetricsCollector)(nil)
+
+func (m *MetricsCollector) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (m *MetricsCollector) AfterQuery(_ context.Context, e *QueryEvent) {
+	buckets := m.buckets()
+	seconds := e.Duration.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ops == nil {
+		m.ops = make(map[QueryOp]*opMetrics)
+	}
+	op, exists := m.ops[e.Op]
+	if !exists {
+		op = &opMetrics{buckets: make([]uint64, len(buckets))}
+		m.ops[e.Op] = op
+	}
+	if e.Err != nil {
+		op.errors++
+	} else {
+		op.ok++
+	}
+	if e.RowsAffected > 0 {
+		op.rowsAffected += uint64(e.RowsAffected)
+	}
+	op.durationSum += seconds
+	if i, _ := slices.BinarySearch(buckets, seconds); i < len(buckets) {
+		op.buckets[i]++
+	}
+}
+
+// WritePrometheus writes all metrics in the Prometheus text exposition format
+func (m *MetricsCollector) WritePrometheus(w io.Writer) error {
+	ns := m.Namespace
+	if ns == "" {
+		ns = "sqldb"
+	}
+	buckets := m.buckets()
+
+	m.mu.Lock()
+	names := slices.Sorted(maps.Keys(m.ops))
+	snapshot := make([]opMetrics, len(names))
+	for i, name := range names {
+		snapshot[i] = *m.ops[name]
+		snapshot[i].buckets = slices.Clone(m.ops[name].buckets)
+	}
+	m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# HELP %s_queries_total Queries by operation and outcome.\n# TYPE %s_queries_total counter\n", ns, ns)
+	for i, op := range snapshot {
+		fmt.Fprintf(bw, "%s_queries_total{op=%q,status=\"ok\"} %d\n", ns, names[i], op.ok)
+		fmt.Fprintf(bw, "%s_queries_total{op=%q,status=\"error\"} %d\n", ns, names[i], op.errors)
+	}
+	fmt.Fprintf(bw, "# HELP %s_query_duration_seconds Query duration by operation.\n# TYPE %s_query_duration_seconds histogram\n", ns, ns)
+	for i, op := range snapshot {
+		var cumulative uint64
+		for j, le := range buckets {
+			cumulative += op.buckets[j]
+			fmt.Fprintf(bw, "%s_query_duration_seconds_bucket{op=%q,le=%q} %d\n",
+				ns, names[i], strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		count := op.ok + op.errors
+		fmt.Fprintf(bw, "%s_query_duration_seconds_bucket{op=%q,le=\"+Inf\"} %d\n", ns, names[i], count)
+		fmt.Fprintf(bw, "%s_query_duration_seconds_sum{op=%q} %s\n", ns, names[i], strconv.FormatFloat(op.durationSum, 'g', -1, 64))
+		fmt.Fprintf(bw, "%s_query_duration_seconds_count{op=%q} %d\n", ns, names[i], count)
+	}
+	fmt.Fprintf(bw, "# HELP %s_rows_affected_total Rows affected by operation.\n# TYPE %s_rows_affected_total counter\n", ns, ns)
+	for i, op := range snapshot {
+		fmt.Fprintf(bw, "%s_rows_affected_total{op=%q} %d\n", ns, names[i], op.rowsAffected)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics, e.g. on /metrics
+func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+func (m *MetricsCollector) buckets() []float64 {
+	if len(m.Buckets) == 0 {
+		return DefaultDurationBuckets
+	}
+	return m.Buckets
+}
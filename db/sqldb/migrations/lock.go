@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+)
+
+// locked runs fn while holding the migration lock, so only one instance migrates at a time.
+// The lock is held by a transaction pinning one connection:
+//   - pgsql: pg_advisory_xact_lock, released when the transaction ends
+//   - mysql: GET_LOCK / RELEASE_LOCK on the transaction's connection
+//   - sqlite: none; the database file is local to one host and writers are serialized
+func (m *Migrator) locked(ctx context.Context, fn func() error) (err error) {
+	if m.DBType == "sqlite" {
+		return fn()
+	}
+	timeout := m.LockTimeout
+	if timeout <= 0 {
+		timeout = defaultLockTimeout
+	}
+
+	tx, err := m.Client.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin lock transaction. %w", err)
+	}
+	rollbackCtx := context.WithoutCancel(ctx)
+	defer func() {
+		_ = tx.Rollback(rollbackCtx)
+	}()
+
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	switch m.DBType {
+	case "pgsql":
+		_, err = tx.Exec(lockCtx, "SELECT pg_advisory_xact_lock($1)", m.lockKey())
+	case "mysql":
+		var got int
+		err = tx.QueryRow(lockCtx, "SELECT COALESCE(GET_LOCK(?, ?), 0)", m.table(), int(timeout.Seconds())).Scan(&got)
+		if err == nil && got != 1 {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		if err == nil {
+			defer func() {
+				var released any
+				_ = tx.QueryRow(rollbackCtx, "SELECT RELEASE_LOCK(?)", m.table()).Scan(&released)
+			}()
+		}
+	default:
+		err = fmt.Errorf("unsupported dbtype %q", m.DBType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to take migration lock. %w", err)
+	}
+	return fn()
+}
+
+// lockKey derives the pgsql advisory lock key from the table name
+func (m *Migrator) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte("sqldb.migrations." + m.table()))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,344 @@
+// Package migrations evolves a schema with versioned SQL files.
+//
+// Files live in one dir of an fs.FS and are named <version>_<name>.<up|down>.<ext>:
+//
+//	0001_create_users.up.sql
+//	0001_create_users.down.sql
+//	0002_add_email.up.sql
+//	0002_add_email.up.pgsql   <- used as-is instead of the .sql file on pgsql
+//
+// Like sqldb.LoadRawStmtsToStore, a file with the dbtype extension overrides the standard SQL file.
+// Migration files run as-is (no placeholder conversion) and may contain multiple statements.
+// Applied versions and checksums of the up files are recorded in a table (default: schema_migrations).
+// NOTE: MySQL commits DDL implicitly, so a failing MySQL migration may be partially applied
+package migrations
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/db/sqldb"
+)
+
+const DefaultTable = "schema_migrations"
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string // empty if there's no down file
+	Checksum string // sha256 hex of Up
+}
+
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool // applied with a different checksum than the current up file
+	Missing   bool // applied, but its files are gone
+}
+
+type Migrator struct {
+	Client sqldb.Client
+	DBType string // pgsql, mysql, sqlite
+	FS     fs.FS
+	Dir    string // dir in FS. default: "."
+	Table  string // default: DefaultTable
+
+	LockTimeout time.Duration // waiting for another instance's lock. default: 1m
+}
+
+var (
+	ErrChecksumMismatch = errors.New("applied migration was modified")
+	ErrOutOfOrder       = errors.New("pending migration is older than the last applied one")
+	ErrNoDown           = errors.New("migration has no down file")
+)
+
+var fileRegex = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.(\w+)$`)
+
+var identRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+const defaultLockTimeout = time.Minute
+
+// Load reads and sorts the migrations for DBType
+func (m *Migrator) Load() ([]Migration, error) {
+	dir := m.Dir
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := fs.ReadDir(m.FS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations dir. %w", err)
+	}
+
+	type files struct {
+		name                             string
+		up, down, dialectUp, dialectDown *string
+	}
+	byVersion := make(map[int64]*files)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		ext := match[4]
+		if ext != "sql" && ext != m.DBType {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid version. %w", entry.Name(), err)
+		}
+		f, exists := byVersion[version]
+		if !exists {
+			f = &files{name: match[2]}
+			byVersion[version] = f
+		} else if f.name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %q and %q", version, f.name, match[2])
+		}
+		data, err := fs.ReadFile(m.FS, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s. %w", entry.Name(), err)
+		}
+		content := string(data)
+		switch {
+		case match[3] == "up" && ext == "sql":
+			f.up = &content
+		case match[3] == "up":
+			f.dialectUp = &content
+		case ext == "sql":
+			f.down = &content
+		default:
+			f.dialectDown = &content
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, f := range byVersion {
+		up := firstNonNil(f.dialectUp, f.up)
+		if up == nil {
+			return nil, fmt.Errorf("migration %d_%s has no up file", version, f.name)
+		}
+		mig := Migration{Version: version, Name: f.name, Up: *up, Checksum: checksum(*up)}
+		if down := firstNonNil(f.dialectDown, f.down); down != nil {
+			mig.Down = *down
+		}
+		migrations = append(migrations, mig)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+func firstNonNil(a, b *string) *string {
+	if a != nil {
+		return a
+	}
+	return b
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Up applies all pending migrations in order, each in its own transaction. Returns the applied ones
+func (m *Migrator) Up(ctx context.Context) (applied []Migration, err error) {
+	err = m.locked(ctx, func() error {
+		migrations, records, err := m.loadState(ctx)
+		if err != nil {
+			return err
+		}
+		var last int64
+		for _, rec := range records {
+			last = max(last, rec.version)
+		}
+		for _, mig := range migrations {
+			rec, exists := records[mig.Version]
+			if exists {
+				if rec.checksum != mig.Checksum {
+					return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, mig.Version, mig.Name)
+				}
+				continue
+			}
+			if mig.Version < last {
+				return fmt.Errorf("%w: %d_%s < %d", ErrOutOfOrder, mig.Version, mig.Name, last)
+			}
+		}
+		for _, mig := range migrations {
+			if _, exists := records[mig.Version]; exists {
+				continue
+			}
+			if err := m.apply(ctx, mig); err != nil {
+				return err
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last n applied migrations (newest first). Returns the reverted ones
+func (m *Migrator) Down(ctx context.Context, n int) (reverted []Migration, err error) {
+	err = m.locked(ctx, func() error {
+		migrations, records, err := m.loadState(ctx)
+		if err != nil {
+			return err
+		}
+		byVersion := make(map[int64]Migration, len(migrations))
+		for _, mig := range migrations {
+			byVersion[mig.Version] = mig
+		}
+		versions := slices.Sorted(maps.Keys(records))
+		slices.Reverse(versions)
+		for _, version := range versions[:min(n, len(versions))] {
+			mig, exists := byVersion[version]
+			if !exists {
+				return fmt.Errorf("applied migration %d (%s) has no files", version, records[version].name)
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("%w: %d_%s", ErrNoDown, mig.Version, mig.Name)
+			}
+			if err := m.revert(ctx, mig); err != nil {
+				return err
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists all migrations known from files or the table, by version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, records, err := m.loadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(migrations))
+	for _, mig := range migrations {
+		status := Status{Version: mig.Version, Name: mig.Name}
+		if rec, exists := records[mig.Version]; exists {
+			status.Applied = true
+			status.AppliedAt = rec.appliedAt
+			status.Modified = rec.checksum != mig.Checksum
+			delete(records, mig.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, rec := range records {
+		statuses = append(statuses, Status{
+			Version: rec.version, Name: rec.name, Applied: true, AppliedAt: rec.appliedAt, Missing: true,
+		})
+	}
+	slices.SortFunc(statuses, func(a, b Status) int { return cmp.Compare(a.Version, b.Version) })
+	return statuses, nil
+}
+
+type record struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+func (m *Migrator) loadState(ctx context.Context) ([]Migration, map[int64]record, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = m.ensureTable(ctx); err != nil {
+		return nil, nil, err
+	}
+	rows, err := m.Client.DBHandle().QueryRows(ctx,
+		"SELECT version, name, checksum, applied_at FROM "+m.table())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s. %w", m.table(), err)
+	}
+	defer rows.Close()
+	records := make(map[int64]record)
+	for rows.Next() {
+		var rec record
+		if err = rows.Scan(&rec.version, &rec.name, &rec.checksum, &rec.appliedAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s. %w", m.table(), err)
+		}
+		records[rec.version] = rec
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s. %w", m.table(), err)
+	}
+	return migrations, records, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	if !identRegex.MatchString(m.table()) {
+		return fmt.Errorf("invalid migrations table name %q", m.table())
+	}
+	_, err := m.Client.DBHandle().Exec(ctx, `CREATE TABLE IF NOT EXISTS `+m.table()+` (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	checksum CHAR(64) NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create %s. %w", m.table(), err)
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, mig Migration) error {
+	insert := sqldb.ConvertStaticPlaceholders(
+		"INSERT INTO "+m.table()+" (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)", m.placeholderPrefix())
+	err := sqldb.WithTx(ctx, m.Client, &sqldb.WithTxOptions{MaxAttempts: 1}, func(tx sqldb.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Up); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, insert, mig.Version, mig.Name, mig.Checksum, time.Now().UTC())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s up failed. %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, mig Migration) error {
+	del := sqldb.ConvertStaticPlaceholders("DELETE FROM "+m.table()+" WHERE version = ?", m.placeholderPrefix())
+	err := sqldb.WithTx(ctx, m.Client, &sqldb.WithTxOptions{MaxAttempts: 1}, func(tx sqldb.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Down); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, del, mig.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s down failed. %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) table() string {
+	if m.Table == "" {
+		return DefaultTable
+	}
+	return m.Table
+}
+
+func (m *Migrator) placeholderPrefix() byte {
+	return sqldb.PlaceholderPrefixForDBType[m.DBType]
+}
@@ -0,0 +1,144 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CompileNamedPlaceholders rewrites named parameters `:name` / `@name` into the positional form
+// of the dialect and returns the parameter name for each positional arg, in order.
+//   - numbered dialects ('$', '@', ':'): a repeated name reuses its number; params are unique
+//   - '?' (and 0): every occurrence is its own `?`; params may repeat
+//
+// Literals, quoted identifiers and comments are skipped like in ConvertStaticPlaceholders,
+// `::` (PostgreSQL casts), `@@` (system variables, e.g. @@session.time_zone) and a bare `@` are left untouched,
+// and `??` is unescaped to `?`.
+// Returns params=nil if sql has no named parameters, and an error if it mixes them with `?` placeholders
+func CompileNamedPlaceholders(sql string, prefix byte) (compiled string, params []string, err error) {
+	var builder strings.Builder
+	builder.Grow(len(sql) + 8)
+	numbered := prefix != '?' && prefix != 0
+	index := make(map[string]int)
+	static := false
+
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, prefix); j > i {
+			builder.WriteString(sql[i:j])
+			i = j
+			continue
+		}
+		c := sql[i]
+		switch {
+		case c == '?':
+			switch {
+			case isEscapedQuestionMark(sql, i):
+				builder.WriteByte('?')
+				i += 2
+			case isJSONBOperator(sql, i, prefix):
+				builder.WriteString(sql[i : i+2])
+				i += 2
+			default:
+				static = true
+				builder.WriteByte('?')
+				i++
+			}
+			continue
+		case c == ':' && i+1 < len(sql) && sql[i+1] == ':':
+			builder.WriteString("::") // cast
+			i += 2
+			continue
+		case c == '@' && i+1 < len(sql) && sql[i+1] == '@':
+			builder.WriteString("@@") // system variable
+			i += 2
+			continue
+		case c != ':' && c != '@',
+			i > 0 && isIdentByte(sql[i-1]),
+			i+1 >= len(sql) || !isIdentStart(sql[i+1]):
+			builder.WriteByte(c)
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(sql) && isIdentByte(sql[j]) {
+			j++
+		}
+		name := sql[i+1 : j]
+		if numbered {
+			n, seen := index[name]
+			if !seen {
+				params = append(params, name)
+				n = len(params)
+				index[name] = n
+			}
+			builder.WriteByte(prefix)
+			builder.WriteString(strconv.Itoa(n))
+		} else {
+			params = append(params, name)
+			builder.WriteByte('?')
+		}
+		i = j
+	}
+	if params == nil {
+		return sql, nil, nil
+	}
+	if static {
+		return "", nil, errors.New("mixing `?` and named placeholders is not supported")
+	}
+	return builder.String(), params, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isIdentByte(c byte) bool {
+	return isIdentStart(c) || '0' <= c && c <= '9'
+}
+
+// BindNamed returns positional args for params, taken from arg:
+// a map[string]any, or a struct (or pointer to one) matched by `db` tags like ScanStruct
+func BindNamed(params []string, arg any) ([]any, error) {
+	args := make([]any, len(params))
+	if m, ok := arg.(map[string]any); ok {
+		for i, name := range params {
+			v, exists := m[name]
+			if !exists {
+				return nil, fmt.Errorf("missing named parameter %q", name)
+			}
+			args[i] = v
+		}
+		return args, nil
+	}
+
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, fmt.Errorf("BindNamed: nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("BindNamed requires map[string]any or struct, got %T", arg)
+	}
+	fields := fieldsOf(v.Type())
+	for i, name := range params {
+		path, exists := fields[strings.ToLower(name)]
+		if !exists {
+			path, exists = fields[name]
+		}
+		if !exists {
+			return nil, fmt.Errorf("missing named parameter %q in %s", name, v.Type())
+		}
+		field, err := v.FieldByIndexErr(path)
+		if err != nil {
+			args[i] = nil // nil embedded pointer
+			continue
+		}
+		args[i] = field.Interface()
+	}
+	return args, nil
+}
@@ -0,0 +1,44 @@
+package sqldb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompileNamedPlaceholders(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		prefix byte
+		want   string
+		params []string
+	}{
+		{"pgsql", "SELECT * FROM t WHERE a = :a AND b = :b OR a = :a", '$', "SELECT * FROM t WHERE a = $1 AND b = $2 OR a = $1", []string{"a", "b"}},
+		{"mysql repeats", "SELECT * FROM t WHERE a = :a AND b = @b OR a = :a", '?', "SELECT * FROM t WHERE a = ? AND b = ? OR a = ?", []string{"a", "b", "a"}},
+		{"no params", "SELECT 1", '$', "SELECT 1", nil},
+		{"cast", "SELECT :v::text", '$', "SELECT $1::text", []string{"v"}},
+		{"literal and comment", "SELECT ':a', \"@b\" -- :c\n, :d", '$', "SELECT ':a', \"@b\" -- :c\n, $1", []string{"d"}},
+		{"bare at and colon", "SELECT a @ b, x : y, :p", '$', "SELECT a @ b, x : y, $1", []string{"p"}},
+		{"inside identifier", "SELECT a:b, :p", '$', "SELECT a:b, $1", []string{"p"}},
+		{"escaped question mark", "SELECT data ?? 'k', :p", '$', "SELECT data ? 'k', $1", []string{"p"}},
+		{"system variable", "SELECT @@version WHERE id = :id", '?', "SELECT @@version WHERE id = ?", []string{"id"}},
+		{"session system variable", "SELECT @@session.time_zone, @@GLOBAL.max_connections, @id", '?', "SELECT @@session.time_zone, @@GLOBAL.max_connections, ?", []string{"id"}},
+		{"system variable only", "SELECT @@version", '?', "SELECT @@version", nil},
+		{"system variable mssql", "SELECT @@ROWCOUNT, @id", '@', "SELECT @@ROWCOUNT, @1", []string{"id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, params, err := CompileNamedPlaceholders(tt.sql, tt.prefix)
+			if err != nil {
+				t.Fatalf("CompileNamedPlaceholders(%q, %q) error: %v", tt.sql, tt.prefix, err)
+			}
+			if got != tt.want || !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("CompileNamedPlaceholders(%q, %q) = %q, %q, want %q, %q", tt.sql, tt.prefix, got, params, tt.want, tt.params)
+			}
+		})
+	}
+
+	if _, _, err := CompileNamedPlaceholders("SELECT ?, :a", '$'); err == nil {
+		t.Error("mixing ? and named placeholders: want error")
+	}
+}
@@ -0,0 +1,343 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/responses"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortColumn is a keyset column. Name is an output column of PageQuery.SQL (trusted, not quoted)
+type SortColumn struct {
+	Name string
+	Desc bool
+}
+
+// PageQuery pages through the result of SQL by keyset (a.k.a. seek method):
+// each page continues after the sort column values of the previous page's last row,
+// so pages stay stable and fast on deep offsets, unlike LIMIT/OFFSET.
+// SQL is a SELECT without ORDER BY/LIMIT; it is wrapped as a derived table.
+// The sort columns must be selected, non-NULL, and unique together (end with a unique column, e.g. id).
+// For pgsql, mysql and sqlite (LIMIT syntax)
+type PageQuery struct {
+	SQL      string
+	Keyset   []SortColumn
+	Prefix   byte     // placeholder prefix of SQL, see PlaceholderPrefixForDBType
+	ScanMode ScanMode // used when scanning by `db` tags
+}
+
+// Page is the response envelope of a page. NextCursor is empty on the last page
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// PageParams are the page request params
+type PageParams struct {
+	Limit  int
+	Cursor string // empty for the first page
+}
+
+// Build returns the page SQL and args: args of SQL followed by the cursor values.
+// cursor is nil for the first page. Fetches limit+1 rows to tell whether there is a next page
+func (pq *PageQuery) Build(cursor []any, limit int, args ...any) (string, []any, error) {
+	if limit < 1 {
+		return "", nil, fmt.Errorf("page limit must be at least 1, not %d", limit)
+	}
+	if len(pq.Keyset) == 0 {
+		return "", nil, fmt.Errorf("PageQuery has no keyset columns")
+	}
+	if cursor != nil && len(cursor) != len(pq.Keyset) {
+		return "", nil, fmt.Errorf("%w: %d values for %d keyset columns", ErrInvalidCursor, len(cursor), len(pq.Keyset))
+	}
+	var sb strings.Builder
+	sb.WriteString("SELECT * FROM (")
+	sb.WriteString(pq.SQL)
+	sb.WriteString(") AS page_")
+	if cursor != nil {
+		sb.WriteString(" WHERE ")
+		args = pq.writeSeek(&sb, cursor, args)
+	}
+	sb.WriteString(" ORDER BY ")
+	for i, col := range pq.Keyset {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(col.Name)
+		if col.Desc {
+			sb.WriteString(" DESC")
+		}
+	}
+	sb.WriteString(" LIMIT ")
+	sb.WriteString(strconv.Itoa(limit + 1))
+	return sb.String(), args, nil
+}
+
+// writeSeek writes `(a > ?) OR (a = ? AND b > ?) OR ...`, which works for mixed sort directions.
+// Numbered placeholders are reused; `?` repeats the arg
+func (pq *PageQuery) writeSeek(sb *strings.Builder, cursor []any, args []any) []any {
+	numbered := pq.Prefix != 0 && pq.Prefix != '?'
+	base := len(args)
+	if numbered {
+		args = append(args, cursor...)
+	}
+	placeholder := func(i int) string {
+		if numbered {
+			return string(pq.Prefix) + strconv.Itoa(base+i+1)
+		}
+		args = append(args, cursor[i])
+		return "?"
+	}
+	for i, col := range pq.Keyset {
+		if i > 0 {
+			sb.WriteString(" OR ")
+		}
+		sb.WriteString("(")
+		for j := range i {
+			sb.WriteString(pq.Keyset[j].Name)
+			sb.WriteString(" = ")
+			sb.WriteString(placeholder(j))
+			sb.WriteString(" AND ")
+		}
+		sb.WriteString(col.Name)
+		if col.Desc {
+			sb.WriteString(" < ")
+		} else {
+			sb.WriteString(" > ")
+		}
+		sb.WriteString(placeholder(i))
+		sb.WriteString(")")
+	}
+	return args
+}
+
+// QueryPage runs the page of params. fieldPtrsFromItem may be nil to scan by `db` tags.
+// params.Limit must be at least 1
+func QueryPage[T any](
+	ctx context.Context,
+	q Queryer,
+	pq *PageQuery,
+	params PageParams,
+	fieldPtrsFromItem func(*T) []any,
+	args ...any,
+) (*Page[T], error) {
+	var cursor []any
+	if params.Cursor != "" {
+		var err error
+		if cursor, err = DecodeCursor(params.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	query, args, err := pq.Build(cursor, params.Limit, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryRows(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keyIndexes, err := pq.keyIndexes(rows)
+	if err != nil {
+		return nil, err
+	}
+	ptrsOf := func(item *T) ([]any, error) { return fieldPtrsFromItem(item), nil }
+	if fieldPtrsFromItem == nil {
+		if ptrsOf, err = structFieldPtrsFunc[T](rows, pq.ScanMode); err != nil {
+			return nil, err
+		}
+	}
+
+	page := &Page[T]{Items: make([]T, 0, params.Limit)}
+	var lastPtrs []any
+	for rows.Next() {
+		if len(page.Items) == params.Limit {
+			// one more row: continue after the last item
+			values := make([]any, len(keyIndexes))
+			for i, index := range keyIndexes {
+				values[i] = reflect.ValueOf(lastPtrs[index]).Elem().Interface()
+			}
+			if page.NextCursor, err = EncodeCursor(values...); err != nil {
+				return nil, err
+			}
+			break
+		}
+		item := new(T)
+		if lastPtrs, err = ptrsOf(item); err != nil {
+			return nil, err
+		}
+		if err = rows.Scan(lastPtrs...); err != nil {
+			return nil, fmt.Errorf("scan failed. %w", err)
+		}
+		page.Items = append(page.Items, *item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during iterating rows. %w", err)
+	}
+	return page, nil
+}
+
+// keyIndexes returns the column indexes of the keyset columns in rows
+func (pq *PageQuery) keyIndexes(rows Rows) ([]int, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(pq.Keyset))
+	for i, col := range pq.Keyset {
+		indexes[i] = -1
+		for j, name := range columns {
+			if strings.EqualFold(name, col.Name) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, fmt.Errorf("keyset column %q is not selected", col.Name)
+		}
+	}
+	return indexes, nil
+}
+
+// cursorValue keeps the Go type of a sort column value across the token, e.g. ints stay ints, times stay times
+type cursorValue struct {
+	Type  string    `json:"t"` // i: int64, f: float64, s: string, b: bool, t: time.Time, x: []byte
+	Int   int64     `json:"i,omitzero"`
+	Float float64   `json:"f,omitzero"`
+	Str   string    `json:"s,omitzero"`
+	Bool  bool      `json:"b,omitzero"`
+	Time  time.Time `json:"tm,omitzero"`
+	Bytes []byte    `json:"x,omitzero"`
+}
+
+// EncodeCursor encodes sort column values into an opaque URL-safe token.
+// Supports ints, floats, strings, bools, time.Time, []byte and driver.Valuers of those (e.g. nullable types)
+func EncodeCursor(values ...any) (string, error) {
+	encoded := make([]cursorValue, len(values))
+	for i, value := range values {
+		if valuer, ok := value.(driver.Valuer); ok {
+			var err error
+			if value, err = valuer.Value(); err != nil {
+				return "", err
+			}
+		}
+		if value == nil {
+			return "", fmt.Errorf("keyset value %d is NULL", i)
+		}
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			encoded[i] = cursorValue{Type: "i", Int: v.Int()}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			encoded[i] = cursorValue{Type: "i", Int: int64(v.Uint())}
+		case reflect.Float32, reflect.Float64:
+			encoded[i] = cursorValue{Type: "f", Float: v.Float()}
+		case reflect.String:
+			encoded[i] = cursorValue{Type: "s", Str: v.String()}
+		case reflect.Bool:
+			encoded[i] = cursorValue{Type: "b", Bool: v.Bool()}
+		default:
+			switch value := value.(type) {
+			case time.Time:
+				encoded[i] = cursorValue{Type: "t", Time: value}
+			case []byte:
+				encoded[i] = cursorValue{Type: "x", Bytes: value}
+			default:
+				return "", fmt.Errorf("unsupported keyset value type %T", value)
+			}
+		}
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes a token of EncodeCursor. ErrInvalidCursor on a malformed token
+func DecodeCursor(token string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var encoded []cursorValue
+	if err = json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	values := make([]any, len(encoded))
+	for i, value := range encoded {
+		switch value.Type {
+		case "i":
+			values[i] = value.Int
+		case "f":
+			values[i] = value.Float
+		case "s":
+			values[i] = value.Str
+		case "b":
+			values[i] = value.Bool
+		case "t":
+			values[i] = value.Time
+		case "x":
+			values[i] = value.Bytes
+		default:
+			return nil, fmt.Errorf("%w: unknown value type %q", ErrInvalidCursor, value.Type)
+		}
+	}
+	return values, nil
+}
+
+// ParsePageParams reads the `limit` and `cursor` query params.
+// limit defaults to defaultLimit and is capped at maxLimit
+func ParsePageParams(r *http.Request, defaultLimit, maxLimit int) (PageParams, error) {
+	params := PageParams{Limit: defaultLimit, Cursor: r.URL.Query().Get("cursor")}
+	if s := r.URL.Query().Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return params, fmt.Errorf("invalid limit %q", s)
+		}
+		params.Limit = limit
+	}
+	if maxLimit > 0 && params.Limit > maxLimit {
+		params.Limit = maxLimit
+	}
+	return params, nil
+}
+
+// QueryPageResponse is QueryAllItemsResponse paged by the `limit` and `cursor` query params,
+// writing a Page envelope. fieldPtrsFromItem may be nil to scan by `db` tags
+func QueryPageResponse[T any](
+	DBHandle DBHandle,
+	pq *PageQuery,
+	fieldPtrsFromItem func(*T) []any,
+	defaultLimit, maxLimit int,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params, err := ParsePageParams(r, defaultLimit, maxLimit)
+		if err != nil {
+			responses.WriteSimpleErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		page, err := QueryPage[T](r.Context(), DBHandle, pq, params, fieldPtrsFromItem)
+		if errors.Is(err, ErrInvalidCursor) {
+			responses.WriteSimpleErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			responses.WriteSimpleErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("[ERROR] SQL failed to query page. %v", err))
+			return
+		}
+		responses.EncodeWriteJSON(w, http.StatusOK, page)
+	}
+}
@@ -0,0 +1,729 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder builds SELECT/INSERT/UPDATE/DELETE/UPSERT stmts for dynamic queries,
+// with the placeholders (PlaceholderPrefixForDBType) and identifier quoting of a DBMS:
+//
+//	qb, err := sqldb.NewQueryBuilder("pgsql")
+//	query, args, err := qb.Select("id", "email").From("users").
+//		Where(sqldb.Eq("status", status), sqldb.In("org_id", orgIDs)).
+//		OrderByDesc("created_at").Limit(20).Build()
+//
+// Table and column names are quoted identifiers (`users.id` -> "users"."id", `users AS u` -> "users" AS "u");
+// use Expr (and SelectExpr, SetExpr) for raw SQL with `?` placeholders.
+// For pgsql, mysql and sqlite
+type QueryBuilder struct {
+	dbtype string
+	prefix byte
+	quote  byte
+}
+
+// NewQueryBuilder - dbtype: pgsql, mysql or sqlite
+func NewQueryBuilder(dbtype string) (*QueryBuilder, error) {
+	switch dbtype {
+	case "pgsql", "sqlite":
+		return &QueryBuilder{dbtype: dbtype, prefix: PlaceholderPrefixForDBType[dbtype], quote: '"'}, nil
+	case "mysql":
+		return &QueryBuilder{dbtype: dbtype, prefix: PlaceholderPrefixForDBType[dbtype], quote: '`'}, nil
+	}
+	return nil, fmt.Errorf("QueryBuilder does not support dbtype %q", dbtype)
+}
+
+// Quote quotes an identifier, keeping `*` and splitting `schema.table.column` and `name AS alias`
+func (qb *QueryBuilder) Quote(ident string) string {
+	var sb strings.Builder
+	qb.writeIdent(&sb, ident)
+	return sb.String()
+}
+
+func (qb *QueryBuilder) writeIdent(sb *strings.Builder, ident string) {
+	if name, alias, found := cutFold(ident, " AS "); found {
+		qb.writeIdent(sb, strings.TrimSpace(name))
+		sb.WriteString(" AS ")
+		qb.writeIdent(sb, strings.TrimSpace(alias))
+		return
+	}
+	for i, part := range strings.Split(ident, ".") {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		if part == "*" {
+			sb.WriteByte('*')
+			continue
+		}
+		sb.WriteByte(qb.quote)
+		sb.WriteString(strings.ReplaceAll(part, string(qb.quote), string([]byte{qb.quote, qb.quote})))
+		sb.WriteByte(qb.quote)
+	}
+}
+
+// cutFold is strings.Cut with a case-insensitive sep
+func cutFold(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(strings.ToUpper(s), sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// stmtWriter accumulates SQL and args, numbering placeholders for the DBMS
+type stmtWriter struct {
+	qb   *QueryBuilder
+	sb   strings.Builder
+	args []any
+	err  error
+}
+
+func (w *stmtWriter) str(s string) {
+	w.sb.WriteString(s)
+}
+
+func (w *stmtWriter) ident(ident string) {
+	w.qb.writeIdent(&w.sb, ident)
+}
+
+func (w *stmtWriter) idents(idents []string) {
+	for i, ident := range idents {
+		if i > 0 {
+			w.str(", ")
+		}
+		w.ident(ident)
+	}
+}
+
+func (w *stmtWriter) arg(v any) {
+	w.args = append(w.args, v)
+	if w.qb.prefix == 0 || w.qb.prefix == '?' {
+		w.sb.WriteByte('?')
+		return
+	}
+	w.sb.WriteByte(w.qb.prefix)
+	w.str(strconv.Itoa(len(w.args)))
+}
+
+// expr writes raw SQL, replacing its `?` placeholders with args (see ConvertStaticPlaceholders)
+func (w *stmtWriter) expr(sql string, args []any) {
+	n := 0
+	for i := 0; i < len(sql); {
+		if j := skipNonCode(sql, i, w.qb.prefix); j > i {
+			w.str(sql[i:j])
+			i = j
+			continue
+		}
+		switch {
+		case sql[i] != '?':
+			w.sb.WriteByte(sql[i])
+			i++
+		case isEscapedQuestionMark(sql, i):
+			w.sb.WriteByte('?')
+			i += 2
+		case isJSONBOperator(sql, i, w.qb.prefix):
+			w.str(sql[i : i+2])
+			i += 2
+		default:
+			if n < len(args) {
+				w.arg(args[n])
+			}
+			n++
+			i++
+		}
+	}
+	if n != len(args) && w.err == nil {
+		w.err = fmt.Errorf("expr %q has %d placeholders for %d args", sql, n, len(args))
+	}
+}
+
+func (w *stmtWriter) where(conds []Cond) {
+	if len(conds) == 0 {
+		return
+	}
+	w.str(" WHERE ")
+	And(conds...).writeCond(w)
+}
+
+func (w *stmtWriter) returning(columns []string) {
+	if len(columns) == 0 {
+		return
+	}
+	if w.qb.dbtype == "mysql" {
+		w.fail(fmt.Errorf("mysql does not support RETURNING"))
+		return
+	}
+	w.str(" RETURNING ")
+	w.idents(columns)
+}
+
+func (w *stmtWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *stmtWriter) build() (string, []any, error) {
+	if w.err != nil {
+		return "", nil, w.err
+	}
+	return w.sb.String(), w.args, nil
+}
+
+// Cond is a WHERE/HAVING condition
+type Cond interface {
+	writeCond(w *stmtWriter)
+}
+
+type compareCond struct {
+	column string
+	op     string
+	value  any
+}
+
+func (c compareCond) writeCond(w *stmtWriter) {
+	w.ident(c.column)
+	if c.value == nil && (c.op == "=" || c.op == "<>") {
+		if c.op == "=" {
+			w.str(" IS NULL")
+		} else {
+			w.str(" IS NOT NULL")
+		}
+		return
+	}
+	w.str(" " + c.op + " ")
+	w.arg(c.value)
+}
+
+// Eq - column = value. nil value: IS NULL
+func Eq(column string, value any) Cond { return compareCond{column, "=", value} }
+
+// Ne - column <> value. nil value: IS NOT NULL
+func Ne(column string, value any) Cond   { return compareCond{column, "<>", value} }
+func Lt(column string, value any) Cond   { return compareCond{column, "<", value} }
+func Le(column string, value any) Cond   { return compareCond{column, "<=", value} }
+func Gt(column string, value any) Cond   { return compareCond{column, ">", value} }
+func Ge(column string, value any) Cond   { return compareCond{column, ">=", value} }
+func Like(column string, value any) Cond { return compareCond{column, "LIKE", value} }
+
+func IsNull(column string) Cond    { return compareCond{column, "=", nil} }
+func IsNotNull(column string) Cond { return compareCond{column, "<>", nil} }
+
+type inCond struct {
+	column string
+	values any
+	not    bool
+}
+
+func (c inCond) writeCond(w *stmtWriter) {
+	v := reflect.ValueOf(c.values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		w.fail(fmt.Errorf("IN values of %q must be a slice, got %T", c.column, c.values))
+		return
+	}
+	if v.Len() == 0 {
+		// IN () is invalid SQL; nothing is in an empty list
+		if c.not {
+			w.str("1 = 1")
+		} else {
+			w.str("1 = 0")
+		}
+		return
+	}
+	w.ident(c.column)
+	if c.not {
+		w.str(" NOT")
+	}
+	w.str(" IN (")
+	for i := range v.Len() {
+		if i > 0 {
+			w.str(", ")
+		}
+		w.arg(v.Index(i).Interface())
+	}
+	w.str(")")
+}
+
+// In expands values (any slice) into `column IN (?, ?, ...)`. An empty slice matches no rows
+func In(column string, values any) Cond { return inCond{column: column, values: values} }
+
+// NotIn - an empty slice matches all rows
+func NotIn(column string, values any) Cond { return inCond{column: column, values: values, not: true} }
+
+type junctionCond struct {
+	op    string
+	conds []Cond
+}
+
+func (c junctionCond) writeCond(w *stmtWriter) {
+	if len(c.conds) == 0 {
+		// neutral element: AND() is true, OR() is false
+		if c.op == " AND " {
+			w.str("1 = 1")
+		} else {
+			w.str("1 = 0")
+		}
+		return
+	}
+	if len(c.conds) == 1 {
+		c.conds[0].writeCond(w)
+		return
+	}
+	for i, cond := range c.conds {
+		if i > 0 {
+			w.str(c.op)
+		}
+		w.str("(")
+		cond.writeCond(w)
+		w.str(")")
+	}
+}
+
+// And skips nil conds, so optional filters can be passed as nil
+func And(conds ...Cond) Cond { return junctionCond{" AND ", compactConds(conds)} }
+
+// Or skips nil conds
+func Or(conds ...Cond) Cond { return junctionCond{" OR ", compactConds(conds)} }
+
+func compactConds(conds []Cond) []Cond {
+	compacted := make([]Cond, 0, len(conds))
+	for _, cond := range conds {
+		if cond != nil {
+			compacted = append(compacted, cond)
+		}
+	}
+	return compacted
+}
+
+// filters reports whether cond is a filter for the UPDATE/DELETE guard: And/Or without conds,
+// NotIn with an empty slice and an OR with such an operand are not
+func filters(cond Cond) bool {
+	switch c := cond.(type) {
+	case inCond:
+		if c.not {
+			v := reflect.ValueOf(c.values)
+			return (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() > 0
+		}
+	case junctionCond:
+		if c.op == " AND " {
+			return slices.ContainsFunc(c.conds, filters)
+		}
+		return len(c.conds) > 0 && !slices.ContainsFunc(c.conds, func(cond Cond) bool { return !filters(cond) })
+	}
+	return true
+}
+
+type notCond struct {
+	cond Cond
+}
+
+func (c notCond) writeCond(w *stmtWriter) {
+	w.str("NOT (")
+	c.cond.writeCond(w)
+	w.str(")")
+}
+
+func Not(cond Cond) Cond { return notCond{cond} }
+
+type exprCond struct {
+	sql  string
+	args []any
+}
+
+func (c exprCond) writeCond(w *stmtWriter) {
+	w.expr(c.sql, c.args)
+}
+
+// Expr is a raw condition with `?` placeholders, e.g. Expr("lower(email) = lower(?)", email)
+func Expr(sql string, args ...any) Cond { return exprCond{sql, args} }
+
+// SelectQuery - build with QueryBuilder.Select
+type SelectQuery struct {
+	qb      *QueryBuilder
+	columns []exprCond // raw: quoted when added
+	from    string
+	joins   []exprCond
+	where   []Cond
+	groupBy []string
+	having  []Cond
+	orderBy []exprCond
+	limit   int
+	offset  int
+}
+
+// Select - no columns: *
+func (qb *QueryBuilder) Select(columns ...string) *SelectQuery {
+	s := &SelectQuery{qb: qb, limit: -1}
+	for _, col := range columns {
+		s.columns = append(s.columns, exprCond{sql: qb.Quote(col)})
+	}
+	return s
+}
+
+// SelectExpr adds a raw column expression, e.g. SelectExpr("COUNT(*) AS n")
+func (s *SelectQuery) SelectExpr(sql string, args ...any) *SelectQuery {
+	s.columns = append(s.columns, exprCond{sql, args})
+	return s
+}
+
+func (s *SelectQuery) From(table string) *SelectQuery {
+	s.from = table
+	return s
+}
+
+// Join adds a raw join clause, e.g. Join("LEFT JOIN orders o ON o.user_id = u.id AND o.status = ?", status)
+func (s *SelectQuery) Join(clause string, args ...any) *SelectQuery {
+	s.joins = append(s.joins, exprCond{clause, args})
+	return s
+}
+
+// Where ANDs conds with those of previous calls. nil conds are skipped
+func (s *SelectQuery) Where(conds ...Cond) *SelectQuery {
+	s.where = append(s.where, compactConds(conds)...)
+	return s
+}
+
+func (s *SelectQuery) GroupBy(columns ...string) *SelectQuery {
+	s.groupBy = append(s.groupBy, columns...)
+	return s
+}
+
+func (s *SelectQuery) Having(conds ...Cond) *SelectQuery {
+	s.having = append(s.having, compactConds(conds)...)
+	return s
+}
+
+func (s *SelectQuery) OrderBy(columns ...string) *SelectQuery {
+	for _, col := range columns {
+		s.orderBy = append(s.orderBy, exprCond{sql: s.qb.Quote(col)})
+	}
+	return s
+}
+
+func (s *SelectQuery) OrderByDesc(columns ...string) *SelectQuery {
+	for _, col := range columns {
+		s.orderBy = append(s.orderBy, exprCond{sql: s.qb.Quote(col) + " DESC"})
+	}
+	return s
+}
+
+// OrderByExpr adds a raw order expression, e.g. OrderByExpr("score DESC NULLS LAST")
+func (s *SelectQuery) OrderByExpr(sql string, args ...any) *SelectQuery {
+	s.orderBy = append(s.orderBy, exprCond{sql, args})
+	return s
+}
+
+func (s *SelectQuery) Limit(n int) *SelectQuery {
+	s.limit = n
+	return s
+}
+
+func (s *SelectQuery) Offset(n int) *SelectQuery {
+	s.offset = n
+	return s
+}
+
+func (s *SelectQuery) Build() (string, []any, error) {
+	w := &stmtWriter{qb: s.qb}
+	if s.from == "" {
+		return "", nil, errors.New("SELECT without FROM")
+	}
+	w.str("SELECT ")
+	if len(s.columns) == 0 {
+		w.str("*")
+	}
+	for i, col := range s.columns {
+		if i > 0 {
+			w.str(", ")
+		}
+		col.writeCond(w)
+	}
+	w.str(" FROM ")
+	w.ident(s.from)
+	for _, join := range s.joins {
+		w.str(" ")
+		join.writeCond(w)
+	}
+	w.where(s.where)
+	if len(s.groupBy) > 0 {
+		w.str(" GROUP BY ")
+		w.idents(s.groupBy)
+	}
+	if len(s.having) > 0 {
+		w.str(" HAVING ")
+		And(s.having...).writeCond(w)
+	}
+	if len(s.orderBy) > 0 {
+		w.str(" ORDER BY ")
+		for i, order := range s.orderBy {
+			if i > 0 {
+				w.str(", ")
+			}
+			order.writeCond(w)
+		}
+	}
+	if s.limit >= 0 {
+		w.str(" LIMIT " + strconv.Itoa(s.limit))
+	}
+	if s.offset > 0 {
+		if s.limit < 0 && s.qb.dbtype != "pgsql" {
+			// mysql and sqlite require LIMIT with OFFSET
+			w.str(" LIMIT " + map[string]string{"mysql": "18446744073709551615", "sqlite": "-1"}[s.qb.dbtype])
+		}
+		w.str(" OFFSET " + strconv.Itoa(s.offset))
+	}
+	return w.build()
+}
+
+// InsertQuery - build with QueryBuilder.Insert. Add OnConflict for an UPSERT
+type InsertQuery struct {
+	qb        *QueryBuilder
+	table     string
+	columns   []string
+	rows      [][]any
+	conflict  []string
+	update    []string
+	doNothing bool
+	upsert    bool
+	returning []string
+}
+
+func (qb *QueryBuilder) Insert(table string) *InsertQuery {
+	return &InsertQuery{qb: qb, table: table}
+}
+
+func (q *InsertQuery) Columns(columns ...string) *InsertQuery {
+	q.columns = columns
+	return q
+}
+
+// Values adds a row; call repeatedly for a multi-row insert
+func (q *InsertQuery) Values(values ...any) *InsertQuery {
+	q.rows = append(q.rows, values)
+	return q
+}
+
+// OnConflict starts an UPSERT on the unique columns; follow with DoUpdate or DoNothing.
+// mysql ignores the columns: ON DUPLICATE KEY UPDATE applies to any unique key
+func (q *InsertQuery) OnConflict(columns ...string) *InsertQuery {
+	q.upsert = true
+	q.conflict = columns
+	return q
+}
+
+// DoUpdate sets columns to the values of the row being inserted.
+// pgsql/sqlite: ON CONFLICT (...) DO UPDATE SET c = EXCLUDED.c; mysql: ON DUPLICATE KEY UPDATE c = VALUES(c)
+func (q *InsertQuery) DoUpdate(columns ...string) *InsertQuery {
+	q.update = columns
+	return q
+}
+
+// DoNothing keeps the existing row. mysql: ON DUPLICATE KEY UPDATE of a column to itself,
+// which unlike INSERT IGNORE doesn't also ignore other errors
+func (q *InsertQuery) DoNothing() *InsertQuery {
+	q.doNothing = true
+	return q
+}
+
+// Returning - pgsql and sqlite only
+func (q *InsertQuery) Returning(columns ...string) *InsertQuery {
+	q.returning = columns
+	return q
+}
+
+func (q *InsertQuery) Build() (string, []any, error) {
+	w := &stmtWriter{qb: q.qb}
+	if len(q.columns) == 0 || len(q.rows) == 0 {
+		return "", nil, errors.New("INSERT without columns or values")
+	}
+	w.str("INSERT INTO ")
+	w.ident(q.table)
+	w.str(" (")
+	w.idents(q.columns)
+	w.str(") VALUES ")
+	for i, row := range q.rows {
+		if len(row) != len(q.columns) {
+			return "", nil, fmt.Errorf("INSERT row %d has %d values for %d columns", i, len(row), len(q.columns))
+		}
+		if i > 0 {
+			w.str(", ")
+		}
+		w.str("(")
+		for j, v := range row {
+			if j > 0 {
+				w.str(", ")
+			}
+			w.arg(v)
+		}
+		w.str(")")
+	}
+	if q.upsert {
+		q.writeUpsert(w)
+	}
+	w.returning(q.returning)
+	return w.build()
+}
+
+func (q *InsertQuery) writeUpsert(w *stmtWriter) {
+	if q.doNothing == (len(q.update) > 0) {
+		w.fail(errors.New("OnConflict requires either DoUpdate or DoNothing"))
+		return
+	}
+	if q.qb.dbtype == "mysql" {
+		w.str(" ON DUPLICATE KEY UPDATE ")
+		if q.doNothing {
+			col := q.columns[0]
+			if len(q.conflict) > 0 {
+				col = q.conflict[0]
+			}
+			w.ident(col)
+			w.str(" = ")
+			w.ident(col)
+			return
+		}
+		for i, col := range q.update {
+			if i > 0 {
+				w.str(", ")
+			}
+			w.ident(col)
+			w.str(" = VALUES(")
+			w.ident(col)
+			w.str(")")
+		}
+		return
+	}
+
+	w.str(" ON CONFLICT")
+	if len(q.conflict) > 0 {
+		w.str(" (")
+		w.idents(q.conflict)
+		w.str(")")
+	} else if !q.doNothing {
+		w.fail(errors.New("DoUpdate requires OnConflict columns"))
+		return
+	}
+	if q.doNothing {
+		w.str(" DO NOTHING")
+		return
+	}
+	w.str(" DO UPDATE SET ")
+	for i, col := range q.update {
+		if i > 0 {
+			w.str(", ")
+		}
+		w.ident(col)
+		w.str(" = EXCLUDED.")
+		w.ident(col)
+	}
+}
+
+// UpdateQuery - build with QueryBuilder.Update
+type UpdateQuery struct {
+	qb        *QueryBuilder
+	table     string
+	sets      []exprCond // `"col" = ?`
+	where     []Cond
+	all       bool
+	returning []string
+}
+
+func (qb *QueryBuilder) Update(table string) *UpdateQuery {
+	return &UpdateQuery{qb: qb, table: table}
+}
+
+func (q *UpdateQuery) Set(column string, value any) *UpdateQuery {
+	q.sets = append(q.sets, exprCond{q.qb.Quote(column) + " = ?", []any{value}})
+	return q
+}
+
+// SetExpr sets a column to a raw expression, e.g. SetExpr("count", "count + ?", 1)
+func (q *UpdateQuery) SetExpr(column string, sql string, args ...any) *UpdateQuery {
+	q.sets = append(q.sets, exprCond{q.qb.Quote(column) + " = " + sql, args})
+	return q
+}
+
+// Where ANDs conds with those of previous calls. nil conds are skipped
+func (q *UpdateQuery) Where(conds ...Cond) *UpdateQuery {
+	q.where = append(q.where, compactConds(conds)...)
+	return q
+}
+
+// All allows an UPDATE without WHERE. Build fails without either, so a dropped filter can't update every row
+func (q *UpdateQuery) All() *UpdateQuery {
+	q.all = true
+	return q
+}
+
+// Returning - pgsql and sqlite only
+func (q *UpdateQuery) Returning(columns ...string) *UpdateQuery {
+	q.returning = columns
+	return q
+}
+
+func (q *UpdateQuery) Build() (string, []any, error) {
+	w := &stmtWriter{qb: q.qb}
+	if len(q.sets) == 0 {
+		return "", nil, errors.New("UPDATE without Set")
+	}
+	if !q.all && !slices.ContainsFunc(q.where, filters) {
+		return "", nil, errors.New("UPDATE without WHERE; call All() to update every row")
+	}
+	w.str("UPDATE ")
+	w.ident(q.table)
+	w.str(" SET ")
+	for i, set := range q.sets {
+		if i > 0 {
+			w.str(", ")
+		}
+		set.writeCond(w)
+	}
+	w.where(q.where)
+	w.returning(q.returning)
+	return w.build()
+}
+
+// DeleteQuery - build with QueryBuilder.Delete
+type DeleteQuery struct {
+	qb        *QueryBuilder
+	table     string
+	where     []Cond
+	all       bool
+	returning []string
+}
+
+func (qb *QueryBuilder) Delete(table string) *DeleteQuery {
+	return &DeleteQuery{qb: qb, table: table}
+}
+
+// Where ANDs conds with those of previous calls. nil conds are skipped
+func (q *DeleteQuery) Where(conds ...Cond) *DeleteQuery {
+	q.where = append(q.where, compactConds(conds)...)
+	return q
+}
+
+// All allows a DELETE without WHERE. Build fails without either, so a dropped filter can't delete every row
+func (q *DeleteQuery) All() *DeleteQuery {
+	q.all = true
+	return q
+}
+
+// Returning - pgsql and sqlite only
+func (q *DeleteQuery) Returning(columns ...string) *DeleteQuery {
+	q.returning = columns
+	return q
+}
+
+func (q *DeleteQuery) Build() (string, []any, error) {
+	w := &stmtWriter{qb: q.qb}
+	if !q.all && !slices.ContainsFunc(q.where, filters) {
+		return "", nil, errors.New("DELETE without WHERE; call All() to delete every row")
+	}
+	w.str("DELETE FROM ")
+	w.ident(q.table)
+	w.where(q.where)
+	w.returning(q.returning)
+	return w.build()
+}
@@ -0,0 +1,165 @@
+package sqldb
+
+import (
+	"reflect"
+	"testing"
+)
+
+type builder interface {
+	Build() (string, []any, error)
+}
+
+func TestQueryBuilder(t *testing.T) {
+	tests := []struct {
+		name   string
+		dbtype string
+		build  func(qb *QueryBuilder) builder
+		want   string
+		args   []any
+	}{
+		{"pgsql select", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Select("u.id", "email AS e").From("public.users AS u").
+				Where(Eq("status", "active"), nil, In("org_id", []int{1, 2}), IsNull("deleted_at")).
+				OrderByDesc("created_at").Limit(20).Offset(40)
+		}, `SELECT "u"."id", "email" AS "e" FROM "public"."users" AS "u" WHERE ("status" = $1) AND ("org_id" IN ($2, $3)) AND ("deleted_at" IS NULL) ORDER BY "created_at" DESC LIMIT 20 OFFSET 40`,
+			[]any{"active", 1, 2}},
+		{"mysql select", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Select("u.id", "email AS e").From("users AS u").
+				Where(Eq("status", "active"), In("org_id", []int{1, 2})).Offset(40)
+		}, "SELECT `u`.`id`, `email` AS `e` FROM `users` AS `u` WHERE (`status` = ?) AND (`org_id` IN (?, ?)) LIMIT 18446744073709551615 OFFSET 40",
+			[]any{"active", 1, 2}},
+		{"sqlite select", "sqlite", func(qb *QueryBuilder) builder {
+			return qb.Select().From("users").Where(Or(Eq("a", 1), Ne("b", nil))).Offset(40)
+		}, `SELECT * FROM "users" WHERE ("a" = ?) OR ("b" IS NOT NULL) LIMIT -1 OFFSET 40`,
+			[]any{1}},
+		{"quote escaping", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Select(`we"ird`).From("t")
+		}, `SELECT "we""ird" FROM "t"`, nil},
+		{"quote escaping mysql", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Select("we`ird").From("t")
+		}, "SELECT `we``ird` FROM `t`", nil},
+		{"pgsql numbering across clauses", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Select().SelectExpr("count(*) FILTER (WHERE x > ?) AS n", 5).From("t").
+				Join("JOIN u ON u.id = t.uid AND u.kind = ?", "k").
+				Where(Expr("lower(email) = lower(?)", "A@B"), Gt("n", 1)).
+				GroupBy("g").Having(Expr("sum(v) > ?", 10))
+		}, `SELECT count(*) FILTER (WHERE x > $1) AS n FROM "t" JOIN u ON u.id = t.uid AND u.kind = $2 WHERE (lower(email) = lower($3)) AND ("n" > $4) GROUP BY "g" HAVING sum(v) > $5`,
+			[]any{5, "k", "A@B", 1, 10}},
+		{"pgsql expr unescaped jsonb ? is a placeholder", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Select().From("t").Where(Expr("data ? 'k' AND data ?| array['?'] AND x = ?", 1)).Where(Expr("y = ?", 2))
+		}, "", nil},
+		{"pgsql expr escaped", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Select().From("t").Where(Expr("data ?? 'k' AND data ?| array['?'] AND x = ?", 1), Expr("y = ?", 2))
+		}, `SELECT * FROM "t" WHERE (data ? 'k' AND data ?| array['?'] AND x = $1) AND (y = $2)`,
+			[]any{1, 2}},
+		{"mysql expr bitwise", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Select().From("t").Where(Expr("flags & ?|4 = 0", 1))
+		}, "SELECT * FROM `t` WHERE flags & ?|4 = 0", []any{1}},
+		{"expr too few args", "sqlite", func(qb *QueryBuilder) builder {
+			return qb.Select().From("t").Where(Expr("a = ? AND b = ?", 1))
+		}, "", nil},
+		{"expr too many args", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Select().From("t").Where(Expr("a = ?", 1, 2))
+		}, "", nil},
+		{"in non-slice", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Select().From("t").Where(In("a", 1))
+		}, "", nil},
+
+		{"pgsql insert", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("a", "b").Values(1, 2).Values(3, 4).Returning("id")
+		}, `INSERT INTO "t" ("a", "b") VALUES ($1, $2), ($3, $4) RETURNING "id"`, []any{1, 2, 3, 4}},
+		{"insert arity", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("a", "b").Values(1)
+		}, "", nil},
+		{"mysql returning", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("a").Values(1).Returning("id")
+		}, "", nil},
+		{"pgsql upsert", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("id", "a").Values(1, 2).OnConflict("id").DoUpdate("a")
+		}, `INSERT INTO "t" ("id", "a") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "a" = EXCLUDED."a"`, []any{1, 2}},
+		{"sqlite upsert do nothing", "sqlite", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("id", "a").Values(1, 2).OnConflict().DoNothing()
+		}, `INSERT INTO "t" ("id", "a") VALUES (?, ?) ON CONFLICT DO NOTHING`, []any{1, 2}},
+		{"mysql upsert", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("id", "a", "b").Values(1, 2, 3).OnConflict("id").DoUpdate("a", "b")
+		}, "INSERT INTO `t` (`id`, `a`, `b`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `a` = VALUES(`a`), `b` = VALUES(`b`)", []any{1, 2, 3}},
+		{"mysql upsert do nothing", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("id", "a").Values(1, 2).OnConflict("id").DoNothing()
+		}, "INSERT INTO `t` (`id`, `a`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `id` = `id`", []any{1, 2}},
+		{"upsert without action", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("id").Values(1).OnConflict("id")
+		}, "", nil},
+		{"do update without conflict columns", "sqlite", func(qb *QueryBuilder) builder {
+			return qb.Insert("t").Columns("id", "a").Values(1, 2).OnConflict().DoUpdate("a")
+		}, "", nil},
+
+		{"pgsql update", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Update("t").Set("a", 1).SetExpr("n", "n + ?", 2).Where(Eq("id", 3)).Returning("n")
+		}, `UPDATE "t" SET "a" = $1, "n" = n + $2 WHERE "id" = $3 RETURNING "n"`, []any{1, 2, 3}},
+		{"mysql update all", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Update("t").Set("a", 1).All()
+		}, "UPDATE `t` SET `a` = ?", []any{1}},
+		{"sqlite delete", "sqlite", func(qb *QueryBuilder) builder {
+			return qb.Delete("t").Where(NotIn("id", []int{1}))
+		}, `DELETE FROM "t" WHERE "id" NOT IN (?)`, []any{1}},
+		{"delete with empty in", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Delete("t").Where(In("id", []int{}))
+		}, `DELETE FROM "t" WHERE 1 = 0`, nil},
+		{"delete with empty and beside a filter", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Delete("t").Where(And(), Eq("id", 1))
+		}, "DELETE FROM `t` WHERE (1 = 1) AND (`id` = ?)", []any{1}},
+		{"delete all with empty not in", "sqlite", func(qb *QueryBuilder) builder {
+			return qb.Delete("t").Where(NotIn("id", []int{})).All()
+		}, `DELETE FROM "t" WHERE 1 = 1`, nil},
+
+		// the guard: UPDATE/DELETE whose conds can't exclude a row fail without All()
+		{"update without where", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Update("t").Set("a", 1)
+		}, "", nil},
+		{"update with nil conds", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Update("t").Set("a", 1).Where(nil, nil)
+		}, "", nil},
+		{"update with and of nil conds", "sqlite", func(qb *QueryBuilder) builder {
+			return qb.Update("t").Set("a", 1).Where(And(nil, nil))
+		}, "", nil},
+		{"delete without where", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Delete("t")
+		}, "", nil},
+		{"delete with empty not in", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Delete("t").Where(NotIn("id", []int{}))
+		}, "", nil},
+		{"delete with empty or", "sqlite", func(qb *QueryBuilder) builder {
+			return qb.Delete("t").Where(Or())
+		}, "", nil},
+		{"delete with or of empty not in", "mysql", func(qb *QueryBuilder) builder {
+			return qb.Delete("t").Where(Or(Eq("id", 1), NotIn("id", []int{})))
+		}, "", nil},
+		{"delete with nested empty junctions", "pgsql", func(qb *QueryBuilder) builder {
+			return qb.Delete("t").Where(And(Or(), And(nil)))
+		}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb, err := NewQueryBuilder(tt.dbtype)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, args, err := tt.build(qb).Build()
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("Build() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("Build() args = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
@@ -1,101 +1,114 @@
 package sqldb
 
 import (
-	"embed"
 	"fmt"
-	"log"
-	"path/filepath"
-	"strings"
+	"maps"
+	"sync"
 )
 
+// RawStore is safe for concurrent use; stmts can be replaced at runtime (see RawStoreRegistry.Watch)
 type RawStore struct {
-	stmts map[string]string
+	mu     sync.RWMutex
+	stmts  map[string]string
+	params map[string][]string // named parameter order of compiled stmts
 }
 
 func NewRawStore() *RawStore {
-	return &RawStore{stmts: make(map[string]string)}
+	return &RawStore{
+		stmts:  make(map[string]string),
+		params: make(map[string][]string),
+	}
 }
 
 func (s *RawStore) Set(key string, rawStmt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.stmts[key] = rawStmt
+	delete(s.params, key)
+}
+
+// SetNamed stores a stmt compiled from named parameters, with the parameter name of each positional arg
+func (s *RawStore) SetNamed(key string, compiledStmt string, params []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stmts[key] = compiledStmt
+	s.params[key] = params
 }
 
 func (s *RawStore) Get(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	stmt, exists := s.stmts[key]
 	return stmt, exists
 }
 
-func (s *RawStore) GetAll() map[string]string {
-	return s.stmts
+// Params returns the named parameter order of a stmt. false if the stmt has no named parameters
+func (s *RawStore) Params(key string) ([]string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	params, exists := s.params[key]
+	return params, exists
 }
 
-type StoreGroupedStmtKey struct {
-	Group    string
-	StmtName string
+// Bind returns the stmt and its positional args taken from arg (map[string]any or struct, see BindNamed)
+func (s *RawStore) Bind(key string, arg any) (string, []any, error) {
+	s.mu.RLock()
+	stmt, exists := s.stmts[key]
+	params, named := s.params[key]
+	s.mu.RUnlock()
+	if !exists {
+		return "", nil, fmt.Errorf("raw stmt %q not found", key)
+	}
+	if !named {
+		return "", nil, fmt.Errorf("raw stmt %q has no named parameters", key)
+	}
+	args, err := BindNamed(params, arg)
+	if err != nil {
+		return "", nil, fmt.Errorf("raw stmt %q: %w", key, err)
+	}
+	return stmt, args, nil
 }
 
-func (k StoreGroupedStmtKey) String() string {
-	return k.Group + "." + k.StmtName
+// GetWithArgs returns the stmt with args as-is; for generated accessors of stmts without named parameters
+func (s *RawStore) GetWithArgs(key string, args ...any) (string, []any, error) {
+	stmt, exists := s.Get(key)
+	if !exists {
+		return "", nil, fmt.Errorf("raw stmt %q not found", key)
+	}
+	return stmt, args, nil
 }
 
-type GroupFS struct {
-	Group string
-	FS    embed.FS
+// GetAll returns a snapshot of all stmts
+func (s *RawStore) GetAll() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.stmts)
 }
 
-var rawStoreRegistry []GroupFS
-
-func RegisterGroup(fs embed.FS, group string) {
-	rawStoreRegistry = append(rawStoreRegistry, GroupFS{
-		FS:    fs,
-		Group: group,
-	})
+// merge sets stmts (with params for the named ones) at once after deleting the stale keys,
+// so readers never see a partially loaded store. Other keys are kept
+func (s *RawStore) merge(stmts map[string]string, params map[string][]string, stale []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range stale {
+		delete(s.stmts, key)
+		delete(s.params, key)
+	}
+	for key, stmt := range stmts {
+		s.stmts[key] = stmt
+		if named, ok := params[key]; ok {
+			s.params[key] = named
+		} else {
+			delete(s.params, key)
+		}
+	}
 }
 
-func LoadRawStmtsToStore(store *RawStore, dbtype string, placeholderPrefix byte) error {
-	groupCnt := 0
-	stmtCnt := 0
-	for _, groupFS := range rawStoreRegistry {
-		files, err := groupFS.FS.ReadDir("sql")
-		if err != nil {
-			return fmt.Errorf("failed to read embedded `sql` dir. %w", err)
-		}
-		for _, f := range files {
-			if f.IsDir() {
-				continue
-			}
-			filename := f.Name()
-			ext := filepath.Ext(filename)
-			name := strings.TrimSuffix(filename, ext)
-			ext = strings.TrimPrefix(ext, ".")
-			data, err := groupFS.FS.ReadFile(filepath.Join("sql", filename))
-			if err != nil {
-				return fmt.Errorf("failed to read %s: %w", filename, err)
-			}
-			groupedStmtKey := StoreGroupedStmtKey{Group: groupFS.Group, StmtName: name}.String()
+type StoreGroupedStmtKey struct {
+	Group    string
+	StmtName string
+}
 
-			switch ext {
-			case dbtype:
-				// exact matching file extension -> use it as-is for dialects
-				store.Set(groupedStmtKey, string(data))
-				stmtCnt++
-			case "sql":
-				// Standard SQL
-				// with Placeholders: `?` (static) and `@` (dynamic)
-				if _, exists := store.Get(groupedStmtKey); !exists {
-					// Convert static placeholders
-					if placeholderPrefix == '?' || placeholderPrefix == 0 {
-						// no need to convert
-						store.Set(groupedStmtKey, string(data))
-					} else {
-						store.Set(groupedStmtKey, ConvertStaticPlaceholders(string(data), placeholderPrefix))
-					}
-					stmtCnt++
-				}
-			}
-		}
-		groupCnt++
-	}
-	log.Printf("[INFO] %d sql raw stmts loaded for %d groups", stmtCnt, groupCnt)
-	return nil
+func (k StoreGroupedStmtKey) String() string {
+	return k.Group + "." + k.StmtName
 }
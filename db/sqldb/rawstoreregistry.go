@@ -0,0 +1,268 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GroupFS is a stmt group. FS must contain a `sql` dir; embed.FS and os.DirFS both work.
+// Files in subdirs are keyed with dots: sql/users/find.sql -> "<group>.users.find"
+type GroupFS struct {
+	Group string
+	FS    fs.FS
+}
+
+// RawStoreRegistry holds stmt groups and loads them into RawStores.
+// For each stmt, a file with the dbtype extension (e.g. find.pgsql) is used as-is
+// in preference to the standard SQL file (find.sql) which is converted for the dialect.
+// If find.sql has named parameters, find.pgsql takes them positionally in order of first appearance
+type RawStoreRegistry struct {
+	mu     sync.RWMutex
+	groups []GroupFS
+}
+
+func NewRawStoreRegistry() *RawStoreRegistry {
+	return &RawStoreRegistry{}
+}
+
+func (r *RawStoreRegistry) RegisterGroup(fsys fs.FS, group string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups = append(r.groups, GroupFS{
+		FS:    fsys,
+		Group: group,
+	})
+}
+
+func (r *RawStoreRegistry) Groups() []GroupFS {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.groups)
+}
+
+// LoadToStore loads all stmts into store, keeping its other keys (as store.Set would).
+// Fails on duplicate keys, unreadable dirs and invalid placeholders
+func (r *RawStoreRegistry) LoadToStore(store *RawStore, dbtype string, placeholderPrefix byte) error {
+	_, err := r.reload(store, dbtype, placeholderPrefix, nil)
+	return err
+}
+
+// reload loads all stmts into store, deleting the stale keys of a previous load that are gone now.
+// Returns the loaded keys
+func (r *RawStoreRegistry) reload(store *RawStore, dbtype string, placeholderPrefix byte, previous []string) ([]string, error) {
+	stmts, params, err := r.load(dbtype, placeholderPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, key := range previous {
+		if _, exists := stmts[key]; !exists {
+			stale = append(stale, key)
+		}
+	}
+	store.merge(stmts, params, stale)
+	log.Printf("[INFO] %d sql raw stmts loaded for %d groups", len(stmts), len(r.Groups()))
+	return slices.Collect(maps.Keys(stmts)), nil
+}
+
+type rawStmtFile struct {
+	path string // <group>:<path in FS>
+	data string
+}
+
+func (r *RawStoreRegistry) load(dbtype string, placeholderPrefix byte) (map[string]string, map[string][]string, error) {
+	standard := make(map[string]rawStmtFile)
+	dialect := make(map[string]rawStmtFile)
+	for _, groupFS := range r.Groups() {
+		err := fs.WalkDir(groupFS.FS, "sql", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			ext := path.Ext(p)
+			var files map[string]rawStmtFile
+			switch strings.TrimPrefix(ext, ".") {
+			case dbtype:
+				files = dialect
+			case "sql":
+				files = standard
+			default:
+				return nil
+			}
+			name := strings.TrimSuffix(strings.TrimPrefix(p, "sql/"), ext)
+			key := StoreGroupedStmtKey{Group: groupFS.Group, StmtName: strings.ReplaceAll(name, "/", ".")}.String()
+			file := rawStmtFile{path: groupFS.Group + ":" + p}
+			if dup, exists := files[key]; exists {
+				return fmt.Errorf("duplicate raw stmt key %q: %s and %s", key, dup.path, file.path)
+			}
+			data, err := fs.ReadFile(groupFS.FS, p)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", file.path, err)
+			}
+			file.data = string(data)
+			files[key] = file
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load `sql` dir of group %q. %w", groupFS.Group, err)
+		}
+	}
+
+	stmts := make(map[string]string, len(standard)+len(dialect))
+	params := make(map[string][]string)
+	for key, file := range dialect {
+		// exact matching file extension -> use it as-is for dialects
+		stmts[key] = file.data
+		// for a standard stmt with named parameters, the dialect stmt takes them by first appearance
+		if std, exists := standard[key]; exists {
+			if _, named, err := CompileNamedPlaceholders(std.data, '$'); err == nil && named != nil {
+				params[key] = named
+			}
+		}
+	}
+	for key, file := range standard {
+		if _, exists := stmts[key]; exists {
+			continue
+		}
+		// Standard SQL
+		// with Placeholders: `?` (static) or `:name` / `@name` (named)
+		compiled, named, err := CompileNamedPlaceholders(file.data, placeholderPrefix)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file.path, err)
+		}
+		if named != nil {
+			stmts[key] = compiled
+			params[key] = named
+		} else {
+			// skips literals and comments; `??` -> `?`
+			stmts[key] = ConvertStaticPlaceholders(file.data, placeholderPrefix)
+		}
+	}
+	return stmts, params, nil
+}
+
+// StmtValidator is implemented by the DBHandles and Txs whose Prepare doesn't compile the stmt (sqlite).
+// Validate uses ValidateStmt instead of Prepare for them
+type StmtValidator interface {
+	ValidateStmt(ctx context.Context, query string) error
+}
+
+// Validate prepares every stmt in store against q (the live DB) and returns all failures joined.
+// Stmts with dynamic placeholders can't be prepared as-is and are skipped
+func (r *RawStoreRegistry) Validate(ctx context.Context, q Queryer, store *RawStore) error {
+	stmts := store.GetAll()
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(stmts)) {
+		stmt := stmts[key]
+		if HasDynamicPlaceholders(stmt) {
+			continue
+		}
+		if validator, ok := q.(StmtValidator); ok {
+			if err := validator.ValidateStmt(ctx, stmt); err != nil {
+				errs = append(errs, fmt.Errorf("raw stmt %q: %w", key, err))
+			}
+			continue
+		}
+		prepared, err := q.Prepare(ctx, stmt)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("raw stmt %q: %w", key, err))
+			continue
+		}
+		if err = prepared.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("raw stmt %q: failed to close prepared stmt. %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Watch polls the group files every interval (default 1s) and reloads store when any of them changes,
+// until ctx is done. Meant for dev builds reading from disk (os.DirFS).
+// A reload replaces the stmts loaded from the registry (removed files drop their keys); keys set otherwise are kept.
+// A reload that fails keeps the previous stmts and logs the error
+func (r *RawStoreRegistry) Watch(ctx context.Context, store *RawStore, dbtype string, placeholderPrefix byte, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	lastSum, err := r.checksum()
+	if err != nil {
+		log.Printf("[WARN] raw stmt watch: %v", err)
+	}
+	var loaded []string // keys of the last load, stale once their files are gone
+	if stmts, _, err := r.load(dbtype, placeholderPrefix); err == nil {
+		loaded = slices.Collect(maps.Keys(stmts))
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			sum, err := r.checksum()
+			if err != nil {
+				log.Printf("[WARN] raw stmt watch: %v", err)
+				continue
+			}
+			if sum == lastSum {
+				continue
+			}
+			lastSum = sum
+			keys, err := r.reload(store, dbtype, placeholderPrefix, loaded)
+			if err != nil {
+				log.Printf("[ERROR] raw stmt reload failed; keeping previous stmts. %v", err)
+				continue
+			}
+			loaded = keys
+			log.Println("[INFO] raw stmts reloaded")
+		}
+	}()
+}
+
+// checksum hashes the paths and contents of all files in the `sql` dirs
+func (r *RawStoreRegistry) checksum() (uint64, error) {
+	h := fnv.New64a()
+	for _, groupFS := range r.Groups() {
+		err := fs.WalkDir(groupFS.FS, "sql", func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := fs.ReadFile(groupFS.FS, p)
+			if err != nil {
+				return err
+			}
+			h.Write([]byte(groupFS.Group + ":" + p + "\x00"))
+			h.Write(data)
+			h.Write([]byte{0})
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("group %q: %w", groupFS.Group, err)
+		}
+	}
+	return h.Sum64(), nil
+}
+
+// DefaultRawStoreRegistry is used by the package-level RegisterGroup and LoadRawStmtsToStore
+var DefaultRawStoreRegistry = NewRawStoreRegistry()
+
+func RegisterGroup(fsys fs.FS, group string) {
+	DefaultRawStoreRegistry.RegisterGroup(fsys, group)
+}
+
+func LoadRawStmtsToStore(store *RawStore, dbtype string, placeholderPrefix byte) error {
+	return DefaultRawStoreRegistry.LoadToStore(store, dbtype, placeholderPrefix)
+}
@@ -0,0 +1,346 @@
+package sqldb
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ReplicaPolicyRoundRobin   = "round_robin" // default
+	ReplicaPolicyLeastLatency = "least_latency"
+)
+
+const (
+	defaultHealthCheckInterval = 5 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+	defaultEjectAfter          = 3
+)
+
+type readYourWritesKey struct{}
+
+// WithReadYourWrites marks ctx so reads through a ReplicatedClient go to the primary,
+// e.g. right after a write whose result must be visible despite replication lag
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+func readYourWrites(ctx context.Context) bool {
+	v, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return v
+}
+
+// ReplicatedClient holds a primary plus N read replicas.
+// QueryRows/QueryRow go to a healthy replica; writes, transactions, Listen/Notify go to the primary.
+// Replicas failing health checks (or losing connections) are ejected until they recover.
+type ReplicatedClient struct {
+	Primary  Client
+	Replicas []Client
+	Policy   string // ReplicaPolicyRoundRobin | ReplicaPolicyLeastLatency
+
+	HealthCheckInterval time.Duration // default: 5s
+	EjectAfter          int           // consecutive failures before ejection. default: 3
+
+	replicas []*replica
+	next     atomic.Uint64
+	stop     context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+type replica struct {
+	client   Client
+	handle   DBHandle
+	healthy  atomic.Bool
+	failures atomic.Int32
+	latency  atomic.Int64 // EWMA in ns
+}
+
+// Ensure ReplicatedClient implements Client interface
+var _ Client = (*ReplicatedClient)(nil)
+
+// NewReplicatedClient creates clients for conf and each of conf.Replicas with newClient
+// (e.g. func(c *sqldb.Conf) sqldb.Client { return &pgsql.Client{Conf: c} }).
+// Replica confs inherit unset fields from the primary conf
+func NewReplicatedClient(conf *Conf, newClient func(conf *Conf) Client) *ReplicatedClient {
+	rc := &ReplicatedClient{
+		Primary: newClient(conf),
+		Policy:  conf.ReplicaPolicy,
+	}
+	for i := range conf.Replicas {
+		rc.Replicas = append(rc.Replicas, newClient(conf.replicaConf(i)))
+	}
+	return rc
+}
+
+// replicaConf merges the replica entry over the primary conf: each field set on the replica wins,
+// Params are merged by key. TLS.ServerName is only inherited when the replica has the primary's Host
+func (c *Conf) replicaConf(i int) *Conf {
+	r := c.Replicas[i]
+	merged := &Conf{
+		Type:   cmp.Or(r.Type, c.Type),
+		Host:   cmp.Or(r.Host, c.Host),
+		Port:   cmp.Or(r.Port, c.Port),
+		Driver: cmp.Or(r.Driver, c.Driver),
+		User:   cmp.Or(r.User, c.User),
+		PW:     cmp.Or(r.PW, c.PW),
+		DB:     cmp.Or(r.DB, c.DB),
+		TZ:     cmp.Or(r.TZ, c.TZ),
+		Pool: PoolConf{
+			MaxConns:        cmp.Or(r.Pool.MaxConns, c.Pool.MaxConns),
+			MinConns:        cmp.Or(r.Pool.MinConns, c.Pool.MinConns),
+			MaxIdleConns:    cmp.Or(r.Pool.MaxIdleConns, c.Pool.MaxIdleConns),
+			MaxConnLifetime: cmp.Or(r.Pool.MaxConnLifetime, c.Pool.MaxConnLifetime),
+			MaxConnIdleTime: cmp.Or(r.Pool.MaxConnIdleTime, c.Pool.MaxConnIdleTime),
+			StmtCacheSize:   cmp.Or(r.Pool.StmtCacheSize, c.Pool.StmtCacheSize),
+		},
+		ConnectTimeout: cmp.Or(r.ConnectTimeout, c.ConnectTimeout),
+		TLS: TLSConf{
+			Mode:       cmp.Or(r.TLS.Mode, c.TLS.Mode),
+			CAFile:     cmp.Or(r.TLS.CAFile, c.TLS.CAFile),
+			CertFile:   cmp.Or(r.TLS.CertFile, c.TLS.CertFile),
+			KeyFile:    cmp.Or(r.TLS.KeyFile, c.TLS.KeyFile),
+			ServerName: r.TLS.ServerName,
+		},
+		AppName: cmp.Or(r.AppName, c.AppName),
+	}
+	if merged.TLS.ServerName == "" && merged.Host == c.Host {
+		// another host would fail verify-full against the primary's name; default to its own Host
+		merged.TLS.ServerName = c.TLS.ServerName
+	}
+	if len(c.Params) > 0 || len(r.Params) > 0 {
+		merged.Params = maps.Clone(c.Params)
+		if merged.Params == nil {
+			merged.Params = make(map[string]string, len(r.Params))
+		}
+		maps.Copy(merged.Params, r.Params)
+	}
+	return merged
+}
+
+func (c *ReplicatedClient) Init() error {
+	if c.Primary == nil {
+		return fmt.Errorf("replicated client has no primary")
+	}
+	if err := c.Primary.Init(); err != nil {
+		return fmt.Errorf("primary init failed: %w", err)
+	}
+	if c.EjectAfter <= 0 {
+		c.EjectAfter = defaultEjectAfter
+	}
+	if c.HealthCheckInterval <= 0 {
+		c.HealthCheckInterval = defaultHealthCheckInterval
+	}
+
+	c.replicas = make([]*replica, 0, len(c.Replicas))
+	for i, client := range c.Replicas {
+		r := &replica{client: client}
+		if err := client.Init(); err != nil {
+			// a replica down at startup shouldn't block the service; health checks re-admit it
+			log.Printf("[WARN] replica #%d init failed, ejected: %v", i, err)
+			_ = client.Close()
+		} else {
+			r.handle = client.DBHandle()
+			r.healthy.Store(true)
+		}
+		c.replicas = append(c.replicas, r)
+	}
+
+	if len(c.replicas) > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.stop = cancel
+		c.wg.Add(1)
+		go c.healthLoop(ctx)
+	}
+	log.Printf("[INFO] replicated client initialized (%d replicas, policy=%s)", len(c.replicas), c.policy())
+	return nil
+}
+
+func (c *ReplicatedClient) Close() error {
+	if c.stop != nil {
+		c.stop()
+		c.wg.Wait()
+	}
+	var errs []error
+	for _, r := range c.replicas {
+		if r.handle == nil {
+			continue // never initialized
+		}
+		if err := r.client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Primary != nil {
+		if err := c.Primary.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *ReplicatedClient) DBHandle() DBHandle {
+	return &replicatedHandle{DBHandle: c.Primary.DBHandle(), client: c}
+}
+
+// BeginTx always runs on the primary
+func (c *ReplicatedClient) BeginTx(ctx context.Context, opts *TxOptions) (Tx, error) {
+	return c.Primary.BeginTx(ctx, opts)
+}
+
+// IsRetryable delegates to the primary, which runs all transactions
+func (c *ReplicatedClient) IsRetryable(err error) bool {
+	classifier, ok := c.Primary.(RetryClassifier)
+	return ok && classifier.IsRetryable(err)
+}
+
+func (c *ReplicatedClient) policy() string {
+	if c.Policy == "" {
+		return ReplicaPolicyRoundRobin
+	}
+	return c.Policy
+}
+
+// pick returns a healthy replica, or nil if reads should go to the primary
+func (c *ReplicatedClient) pick(ctx context.Context) *replica {
+	if len(c.replicas) == 0 || readYourWrites(ctx) {
+		return nil
+	}
+	if c.policy() == ReplicaPolicyLeastLatency {
+		var best *replica
+		for _, r := range c.replicas {
+			if r.healthy.Load() && (best == nil || latencyRank(r) < latencyRank(best)) {
+				best = r
+			}
+		}
+		return best
+	}
+	n := uint64(len(c.replicas))
+	start := c.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := c.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// latencyRank orders replicas by latency, unmeasured (0) last
+func latencyRank(r *replica) int64 {
+	if latency := r.latency.Load(); latency > 0 {
+		return latency
+	}
+	return math.MaxInt64
+}
+
+// reportErr counts connection-level failures toward ejection
+func (c *ReplicatedClient) reportErr(r *replica, err error) {
+	if !errors.Is(err, ErrConnectionLost) {
+		return
+	}
+	if int(r.failures.Add(1)) >= c.EjectAfter && r.healthy.CompareAndSwap(true, false) {
+		log.Printf("[WARN] replica ejected after %d failures: %v", c.EjectAfter, err)
+	}
+}
+
+func (c *ReplicatedClient) healthLoop(ctx context.Context) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for i, r := range c.replicas {
+			c.checkReplica(ctx, i, r)
+		}
+	}
+}
+
+func (c *ReplicatedClient) checkReplica(ctx context.Context, i int, r *replica) {
+	if r.handle == nil {
+		// failed at Init; retry it, closing whatever the failed attempt left open
+		if err := r.client.Init(); err != nil {
+			_ = r.client.Close()
+			return
+		}
+		r.handle = r.client.DBHandle()
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, defaultHealthCheckTimeout)
+	defer cancel()
+	started := time.Now()
+	var one int
+	err := r.handle.QueryRow(checkCtx, "SELECT 1").Scan(&one)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		if int(r.failures.Add(1)) >= c.EjectAfter && r.healthy.CompareAndSwap(true, false) {
+			log.Printf("[WARN] replica #%d ejected: %v", i, err)
+		}
+		return
+	}
+
+	elapsed := time.Since(started).Nanoseconds()
+	if prev := r.latency.Load(); prev == 0 {
+		r.latency.Store(elapsed)
+	} else {
+		r.latency.Store((prev*7 + elapsed) / 8) // EWMA, alpha = 1/8
+	}
+	r.failures.Store(0)
+	if r.healthy.CompareAndSwap(false, true) {
+		log.Printf("[INFO] replica #%d re-admitted", i)
+	}
+}
+
+// replicatedHandle embeds the primary's DBHandle and overrides the read paths
+type replicatedHandle struct {
+	DBHandle
+	client *ReplicatedClient
+}
+
+func (h *replicatedHandle) QueryRows(ctx context.Context, query string, args ...any) (Rows, error) {
+	r := h.client.pick(ctx)
+	if r == nil {
+		return h.DBHandle.QueryRows(ctx, query, args...)
+	}
+	rows, err := r.handle.QueryRows(ctx, query, args...)
+	if err != nil && errors.Is(err, ErrConnectionLost) {
+		h.client.reportErr(r, err)
+		return h.DBHandle.QueryRows(ctx, query, args...) // fall back to primary
+	}
+	return rows, err
+}
+
+func (h *replicatedHandle) QueryRow(ctx context.Context, query string, args ...any) Row {
+	r := h.client.pick(ctx)
+	if r == nil {
+		return h.DBHandle.QueryRow(ctx, query, args...)
+	}
+	return &replicaRow{
+		Row:    r.handle.QueryRow(ctx, query, args...),
+		report: func(err error) { h.client.reportErr(r, err) },
+	}
+}
+
+// replicaRow reports connection failures surfacing at Scan (QueryRow is lazy)
+type replicaRow struct {
+	Row
+	report func(err error)
+}
+
+func (r *replicaRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	if err != nil {
+		r.report(err)
+	}
+	return err
+}
@@ -1,14 +1,20 @@
 package sqldb
 
 type Rows interface {
+	// Columns returns the result column names of the current result set
+	Columns() ([]string, error)
+	// ColumnTypes returns column metadata of the current result set
+	ColumnTypes() ([]ColumnType, error)
 	Next() bool
 	Scan(dest ...any) error
 	Close() error
 	Err() error
+	// NextResultSet advances to the next result set of a batch. false if there is none
 	NextResultSet() bool
 }
 
 type Row interface {
+	Columns() ([]string, error)
 	Scan(dest ...any) error
 }
 
@@ -16,3 +22,19 @@ type Result interface {
 	RowsAffected() (int64, error)
 	LastInsertId() (int64, error)
 }
+
+// ColumnType is a portable column descriptor. Has* flags tell whether the DBMS/driver reported the value
+type ColumnType struct {
+	Name         string
+	DatabaseType string // DBMS type name, upper case e.g. "VARCHAR", "INT4", "DECIMAL"
+
+	Nullable    bool
+	HasNullable bool
+
+	Length    int64 // variable length text/binary types
+	HasLength bool
+
+	Precision    int64 // decimal types
+	Scale        int64
+	HasPrecision bool
+}
@@ -0,0 +1,173 @@
+package sqldb
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+const defaultStmtCacheCapacity = 256
+
+// StmtCache reuses PreparedStmts by SQL or RawStore key, closing the least recently used beyond capacity.
+// Impls prepare lazily on the connection that runs a stmt and re-prepare on new connections,
+// so cached stmts don't pin connections
+type StmtCache struct {
+	q        Queryer
+	store    *RawStore // for the *Key methods. optional
+	capacity int
+
+	mu    sync.Mutex
+	lru   *list.List // of *cachedStmt, least recently used at the back
+	elems map[string]*list.Element
+}
+
+type cachedStmt struct {
+	sql  string
+	mu   sync.RWMutex // held for reading while the stmt runs, so eviction closes it only when idle
+	stmt PreparedStmt
+}
+
+// NewStmtCache - store may be nil if stmts are only looked up by SQL. capacity <= 0: 256
+func NewStmtCache(q Queryer, store *RawStore, capacity int) *StmtCache {
+	if capacity <= 0 {
+		capacity = defaultStmtCacheCapacity
+	}
+	return &StmtCache{
+		q:        q,
+		store:    store,
+		capacity: capacity,
+		lru:      list.New(),
+		elems:    make(map[string]*list.Element),
+	}
+}
+
+func (c *StmtCache) Exec(ctx context.Context, query string, args ...any) (Result, error) {
+	entry, err := c.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.mu.RUnlock()
+	return entry.stmt.Exec(ctx, args...)
+}
+
+func (c *StmtCache) QueryRows(ctx context.Context, query string, args ...any) (Rows, error) {
+	entry, err := c.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.mu.RUnlock()
+	return entry.stmt.Query(ctx, args...)
+}
+
+// ExecKey runs the RawStore stmt of key. After a RawStore reload, the new SQL is prepared on first use
+func (c *StmtCache) ExecKey(ctx context.Context, key string, args ...any) (Result, error) {
+	query, err := c.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.Exec(ctx, query, args...)
+}
+
+func (c *StmtCache) QueryRowsKey(ctx context.Context, key string, args ...any) (Rows, error) {
+	query, err := c.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.QueryRows(ctx, query, args...)
+}
+
+// Len returns the number of cached stmts
+func (c *StmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Close closes all cached stmts. The cache stays usable
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	evicted := make([]*cachedStmt, 0, c.lru.Len())
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		evicted = append(evicted, elem.Value.(*cachedStmt))
+	}
+	c.lru.Init()
+	clear(c.elems)
+	c.mu.Unlock()
+	return closeStmts(evicted)
+}
+
+func (c *StmtCache) lookup(key string) (string, error) {
+	if c.store == nil {
+		return "", fmt.Errorf("StmtCache has no RawStore")
+	}
+	query, exists := c.store.Get(key)
+	if !exists {
+		return "", fmt.Errorf("raw stmt %q not found", key)
+	}
+	return query, nil
+}
+
+// get returns the cached stmt for query, read-locked; the caller must RUnlock it after running it
+func (c *StmtCache) get(ctx context.Context, query string) (*cachedStmt, error) {
+	for {
+		c.mu.Lock()
+		elem, exists := c.elems[query]
+		if exists {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			entry := elem.Value.(*cachedStmt)
+			entry.mu.RLock()
+			if entry.stmt != nil {
+				return entry, nil
+			}
+			// closed by eviction meanwhile
+			entry.mu.RUnlock()
+			continue
+		}
+		c.mu.Unlock()
+
+		// prepare outside the lock; a concurrent miss for the same query may prepare it twice
+		stmt, err := c.q.Prepare(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		entry := &cachedStmt{sql: query, stmt: stmt}
+		entry.mu.RLock()
+
+		c.mu.Lock()
+		if elem, exists = c.elems[query]; exists {
+			c.mu.Unlock()
+			entry.mu.RUnlock()
+			_ = stmt.Close()
+			continue
+		}
+		c.elems[query] = c.lru.PushFront(entry)
+		var evicted []*cachedStmt
+		for c.lru.Len() > c.capacity {
+			oldest := c.lru.Remove(c.lru.Back()).(*cachedStmt)
+			delete(c.elems, oldest.sql)
+			evicted = append(evicted, oldest)
+		}
+		c.mu.Unlock()
+		if len(evicted) > 0 {
+			go func() { _ = closeStmts(evicted) }() // waits for callers still running them
+		}
+		return entry, nil
+	}
+}
+
+func closeStmts(entries []*cachedStmt) error {
+	var firstErr error
+	for _, entry := range entries {
+		entry.mu.Lock()
+		if entry.stmt != nil {
+			if err := entry.stmt.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			entry.stmt = nil
+		}
+		entry.mu.Unlock()
+	}
+	return firstErr
+}
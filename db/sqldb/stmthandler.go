@@ -0,0 +1,465 @@
+package sqldb
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LearnLoop365/flxr-core/responses"
+)
+
+const defaultMaxBodyBytes = 1 << 20
+
+// StmtHandler builds handlers running a RawStore stmt with args bound from the request:
+//
+//	find := sqldb.NewStmtHandler(dbHandle, store, "user.find").Path("id", sqldb.ParamInt)
+//	mux.Handle("GET /users/{id}", sqldb.ItemResponse[User](find, nil))
+//
+// Params are bound by name if the stmt has named parameters (see RawStore.Bind), in declaration order otherwise.
+// Invalid input -> 400 listing every invalid param
+type StmtHandler struct {
+	q                        Queryer
+	store                    *RawStore
+	key                      string
+	params                   []*stmtParam
+	maxBodyBytes             int64
+	notFoundIfNoRowsAffected bool
+}
+
+type paramSource int
+
+const (
+	fromPath paramSource = iota
+	fromQuery
+	fromBody
+)
+
+func (s paramSource) String() string {
+	return [...]string{"path value", "query param", "body field"}[s]
+}
+
+type stmtParam struct {
+	name     string
+	source   paramSource
+	typ      ParamType
+	optional bool
+	fallback any
+	checks   []func(v any) error
+}
+
+// ParamType converts an input to a stmt arg
+type ParamType struct {
+	Name   string
+	Parse  func(s string) (any, error)         // path values and query params
+	Decode func(v jsontext.Value) (any, error) // body fields
+}
+
+var (
+	ParamString = ParamType{
+		Name:   "string",
+		Parse:  func(s string) (any, error) { return s, nil },
+		Decode: decodeJSONAs[string],
+	}
+	ParamInt = ParamType{
+		Name:   "integer",
+		Parse:  func(s string) (any, error) { return strconv.ParseInt(s, 10, 64) },
+		Decode: decodeJSONAs[int64],
+	}
+	ParamFloat = ParamType{
+		Name:   "number",
+		Parse:  func(s string) (any, error) { return strconv.ParseFloat(s, 64) },
+		Decode: decodeJSONAs[float64],
+	}
+	ParamBool = ParamType{
+		Name:   "boolean",
+		Parse:  func(s string) (any, error) { return strconv.ParseBool(s) },
+		Decode: decodeJSONAs[bool],
+	}
+	ParamTime = ParamType{ // RFC 3339
+		Name:   "RFC 3339 time",
+		Parse:  func(s string) (any, error) { return time.Parse(time.RFC3339Nano, s) },
+		Decode: decodeJSONAs[time.Time],
+	}
+)
+
+func decodeJSONAs[T any](v jsontext.Value) (any, error) {
+	var dest T
+	if err := json.Unmarshal(v, &dest); err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// ParamOption configures a param. Params are required unless Optional
+type ParamOption func(p *stmtParam)
+
+// Optional makes a param optional, bound to fallback (nil: NULL) if missing
+func Optional(fallback any) ParamOption {
+	return func(p *stmtParam) {
+		p.optional = true
+		p.fallback = fallback
+	}
+}
+
+// Check adds a validation of the converted value
+func Check(check func(v any) error) ParamOption {
+	return func(p *stmtParam) {
+		p.checks = append(p.checks, check)
+	}
+}
+
+// Min requires an integer or number param >= min
+func Min(min float64) ParamOption {
+	return Check(func(v any) error {
+		if n, ok := toFloat(v); ok && n < min {
+			return fmt.Errorf("must be >= %v", min)
+		}
+		return nil
+	})
+}
+
+// Max requires an integer or number param <= max
+func Max(max float64) ParamOption {
+	return Check(func(v any) error {
+		if n, ok := toFloat(v); ok && n > max {
+			return fmt.Errorf("must be <= %v", max)
+		}
+		return nil
+	})
+}
+
+// MinLen requires a string param of at least n characters
+func MinLen(n int) ParamOption {
+	return Check(func(v any) error {
+		if s, ok := v.(string); ok && len([]rune(s)) < n {
+			return fmt.Errorf("must be at least %d characters", n)
+		}
+		return nil
+	})
+}
+
+// MaxLen requires a string param of at most n characters
+func MaxLen(n int) ParamOption {
+	return Check(func(v any) error {
+		if s, ok := v.(string); ok && len([]rune(s)) > n {
+			return fmt.Errorf("must be at most %d characters", n)
+		}
+		return nil
+	})
+}
+
+// OneOf requires the param to equal one of values (of the param's Go type, e.g. int64 for ParamInt)
+func OneOf(values ...any) ParamOption {
+	return Check(func(v any) error {
+		if !slices.Contains(values, v) {
+			return fmt.Errorf("must be one of %v", values)
+		}
+		return nil
+	})
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// NewStmtHandler - the stmt of key is looked up per request, so RawStore reloads apply
+func NewStmtHandler(q Queryer, store *RawStore, key string) *StmtHandler {
+	return &StmtHandler{q: q, store: store, key: key, maxBodyBytes: defaultMaxBodyBytes}
+}
+
+// Path binds r.PathValue(name). Always required
+func (h *StmtHandler) Path(name string, typ ParamType, opts ...ParamOption) *StmtHandler {
+	return h.param(name, fromPath, typ, opts)
+}
+
+// Query binds the query param name
+func (h *StmtHandler) Query(name string, typ ParamType, opts ...ParamOption) *StmtHandler {
+	return h.param(name, fromQuery, typ, opts)
+}
+
+// Body binds the field name of a JSON object body. null counts as missing
+func (h *StmtHandler) Body(name string, typ ParamType, opts ...ParamOption) *StmtHandler {
+	return h.param(name, fromBody, typ, opts)
+}
+
+// MaxBodyBytes limits the JSON body. default: 1MB
+func (h *StmtHandler) MaxBodyBytes(n int64) *StmtHandler {
+	h.maxBodyBytes = n
+	return h
+}
+
+// NotFoundIfNoRowsAffected makes ExecResponse write 404 if the stmt affected no rows, e.g. UPDATE/DELETE by id
+func (h *StmtHandler) NotFoundIfNoRowsAffected() *StmtHandler {
+	h.notFoundIfNoRowsAffected = true
+	return h
+}
+
+func (h *StmtHandler) param(name string, source paramSource, typ ParamType, opts []ParamOption) *StmtHandler {
+	p := &stmtParam{name: name, source: source, typ: typ}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if source == fromPath {
+		p.optional = false
+	}
+	h.params = append(h.params, p)
+	return h
+}
+
+// Bind returns the stmt and its args from r. A *BindError for invalid input
+func (h *StmtHandler) Bind(r *http.Request) (string, []any, error) {
+	values, err := h.bindValues(r)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, named := h.store.Params(h.key); named {
+		return h.store.Bind(h.key, values)
+	}
+	args := make([]any, len(h.params))
+	for i, p := range h.params {
+		args[i] = values[p.name]
+	}
+	return h.store.GetWithArgs(h.key, args...)
+}
+
+// BindError lists the invalid params of a request
+type BindError struct {
+	Errs []error
+}
+
+func (e *BindError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *BindError) Unwrap() []error {
+	return e.Errs
+}
+
+func (h *StmtHandler) bindValues(r *http.Request) (map[string]any, error) {
+	var (
+		bindErr BindError
+		body    map[string]jsontext.Value
+		query   = r.URL.Query()
+	)
+	if slices.ContainsFunc(h.params, func(p *stmtParam) bool { return p.source == fromBody }) {
+		data, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, h.maxBodyBytes))
+		if err != nil {
+			return nil, &BindError{Errs: []error{fmt.Errorf("failed to read body. %v", err)}}
+		}
+		// an empty body has no fields
+		if len(bytes.TrimSpace(data)) > 0 {
+			if err = json.Unmarshal(data, &body); err != nil {
+				return nil, &BindError{Errs: []error{fmt.Errorf("invalid JSON body. %v", err)}}
+			}
+		}
+	}
+
+	values := make(map[string]any, len(h.params))
+	for _, p := range h.params {
+		var (
+			value   any
+			present bool
+			err     error
+		)
+		switch p.source {
+		case fromPath:
+			if s := r.PathValue(p.name); s != "" {
+				value, err = p.typ.Parse(s)
+				present = true
+			}
+		case fromQuery:
+			if query.Has(p.name) {
+				value, err = p.typ.Parse(query.Get(p.name))
+				present = true
+			}
+		case fromBody:
+			if raw, exists := body[p.name]; exists && raw.Kind() != 'n' {
+				value, err = p.typ.Decode(raw)
+				present = true
+			}
+		}
+		if err != nil {
+			bindErr.Errs = append(bindErr.Errs, fmt.Errorf("%s %q must be %s", p.source, p.name, withArticle(p.typ.Name)))
+			continue
+		}
+		if !present {
+			if !p.optional {
+				bindErr.Errs = append(bindErr.Errs, fmt.Errorf("%s %q is required", p.source, p.name))
+				continue
+			}
+			values[p.name] = p.fallback
+			continue
+		}
+		for _, check := range p.checks {
+			if err = check(value); err != nil {
+				bindErr.Errs = append(bindErr.Errs, fmt.Errorf("%s %q %v", p.source, p.name, err))
+				break
+			}
+		}
+		values[p.name] = value
+	}
+	if len(bindErr.Errs) > 0 {
+		return nil, &bindErr
+	}
+	return values, nil
+}
+
+// withArticle returns "a s" or "an s", or "valid" for an unnamed ParamType
+func withArticle(s string) string {
+	if len(s) == 0 {
+		return "valid"
+	}
+	if strings.ContainsRune("aeiouAEIOU", rune(s[0])) {
+		return "an " + s
+	}
+	return "a " + s
+}
+
+// ItemResponse writes the first row as a JSON object, 404 if there is none.
+// fieldPtrsFromItem may be nil to scan by `db` tags (ScanStrict)
+func ItemResponse[T any](h *StmtHandler, fieldPtrsFromItem func(*T) []any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, args, ok := h.bind(w, r)
+		if !ok {
+			return
+		}
+		var item T
+		row := h.q.QueryRow(r.Context(), query, args...)
+		var err error
+		if fieldPtrsFromItem == nil {
+			err = ScanStruct(row, &item, ScanStrict)
+		} else {
+			err = row.Scan(fieldPtrsFromItem(&item)...)
+		}
+		if err != nil {
+			writeStmtError(w, "query item", err)
+			return
+		}
+		responses.EncodeWriteJSON(w, http.StatusOK, item)
+	}
+}
+
+// ListResponse writes all rows as a JSON array.
+// fieldPtrsFromItem may be nil to scan by `db` tags (ScanStrict)
+func ListResponse[T any](h *StmtHandler, fieldPtrsFromItem func(*T) []any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, args, ok := h.bind(w, r)
+		if !ok {
+			return
+		}
+		rows, err := h.q.QueryRows(r.Context(), query, args...)
+		if err != nil {
+			writeStmtError(w, "query items", err)
+			return
+		}
+		var items []T
+		if fieldPtrsFromItem == nil {
+			items, err = RowsToStructs[T](rows, ScanStrict)
+		} else {
+			items, err = RowsToItems(rows, fieldPtrsFromItem)
+			_ = rows.Close()
+		}
+		if err != nil {
+			writeStmtError(w, "query items", err)
+			return
+		}
+		if items == nil {
+			items = []T{}
+		}
+		responses.EncodeWriteJSON(w, http.StatusOK, items)
+	}
+}
+
+// ExecResult is the response of ExecResponse and InsertResponse
+type ExecResult struct {
+	RowsAffected int64  `json:"rows_affected"`
+	LastInsertID *int64 `json:"last_insert_id,omitempty"`
+}
+
+// ExecResponse runs the stmt with Exec and writes an ExecResult
+func (h *StmtHandler) ExecResponse() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, args, ok := h.bind(w, r)
+		if !ok {
+			return
+		}
+		result, err := h.q.Exec(r.Context(), query, args...)
+		if err != nil {
+			writeStmtError(w, "exec", err)
+			return
+		}
+		res := ExecResult{RowsAffected: RowsAffectedOf(result)}
+		if res.RowsAffected == 0 && h.notFoundIfNoRowsAffected {
+			responses.WriteSimpleErrorJSON(w, http.StatusNotFound, responses.HTTPErrorNotFound.Error())
+			return
+		}
+		responses.EncodeWriteJSON(w, http.StatusOK, res)
+	}
+}
+
+// InsertResponse runs the stmt with InsertStmt and writes 201 with an ExecResult including last_insert_id
+func (h *StmtHandler) InsertResponse() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, args, ok := h.bind(w, r)
+		if !ok {
+			return
+		}
+		result, err := h.q.InsertStmt(r.Context(), query, args...)
+		if err != nil {
+			writeStmtError(w, "insert", err)
+			return
+		}
+		res := ExecResult{RowsAffected: RowsAffectedOf(result)}
+		if id, err := result.LastInsertId(); err == nil {
+			res.LastInsertID = &id
+		}
+		responses.EncodeWriteJSON(w, http.StatusCreated, res)
+	}
+}
+
+// bind writes 400 for invalid input, 500 for other errors
+func (h *StmtHandler) bind(w http.ResponseWriter, r *http.Request) (string, []any, bool) {
+	query, args, err := h.Bind(r)
+	if err != nil {
+		var bindErr *BindError
+		if errors.As(err, &bindErr) {
+			responses.WriteSimpleErrorJSON(w, http.StatusBadRequest, bindErr.Error())
+		} else {
+			responses.WriteSimpleErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("[ERROR] SQL failed to bind args. %v", err))
+		}
+		return "", nil, false
+	}
+	return query, args, true
+}
+
+// writeStmtError maps ErrNoRows to 404 and constraint violations to 409/422
+func writeStmtError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, ErrNoRows):
+		responses.WriteSimpleErrorJSON(w, http.StatusNotFound, responses.HTTPErrorNotFound.Error())
+	case errors.Is(err, ErrUniqueViolation):
+		responses.WriteSimpleErrorJSON(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrForeignKeyViolation), errors.Is(err, ErrNotNullViolation), errors.Is(err, ErrCheckViolation):
+		responses.WriteSimpleErrorJSON(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		responses.WriteSimpleErrorJSON(w, http.StatusInternalServerError, fmt.Sprintf("[ERROR] SQL failed to %s. %v", action, err))
+	}
+}
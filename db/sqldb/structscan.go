@@ -0,0 +1,194 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ScanMode controls what happens to result columns with no matching struct field
+type ScanMode int
+
+const (
+	ScanStrict  ScanMode = iota // unmapped column -> error (default; catches SELECT/struct drift)
+	ScanLenient                 // unmapped column -> discarded
+)
+
+// ColumnScanner is satisfied by both Rows and Row
+type ColumnScanner interface {
+	Columns() ([]string, error)
+	Scan(dest ...any) error
+}
+
+// structFields maps column names to field index paths of a struct type
+type structFields map[string][]int
+
+var structFieldsCache sync.Map // map[reflect.Type]structFields
+
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+// fieldsOf returns the (cached) column -> field mapping of struct type t.
+// Column names come from `db:"name"` tags; untagged exported fields use the lower-cased field name;
+// `db:"-"` skips a field. Embedded structs without a tag are flattened (outer fields win),
+// except embedded pointers to unexported struct types, which are skipped.
+// Struct types implementing sql.Scanner (e.g. nullable.String, sql.NullInt64) and time.Time are leaf fields
+func fieldsOf(t reflect.Type) structFields {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.(structFields)
+	}
+	fields := make(structFields)
+	collectFields(t, nil, fields, make(map[string]int))
+	cached, _ := structFieldsCache.LoadOrStore(t, fields)
+	return cached.(structFields)
+}
+
+// collectFields walks t breadth-first by depth so shallower fields shadow embedded ones
+func collectFields(t reflect.Type, index []int, fields structFields, depths map[string]int) {
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	var nested []embedded
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("db")
+		tag, _, _ = strings.Cut(tag, ",")
+		if tag == "-" {
+			continue
+		}
+		path := append(append([]int(nil), index...), i)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && (!hasTag || tag == "") && ft.Kind() == reflect.Struct && !isLeafStruct(ft) {
+			if !f.IsExported() && f.Type.Kind() == reflect.Pointer {
+				continue // reflect can't allocate it when nil
+			}
+			nested = append(nested, embedded{t: ft, index: path})
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if depth, exists := depths[name]; exists && depth <= len(index) {
+			continue // shadowed by a shallower field
+		}
+		depths[name] = len(index)
+		fields[name] = path
+	}
+	for _, e := range nested {
+		collectFields(e.t, e.index, fields, depths)
+	}
+}
+
+func isLeafStruct(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(scannerType) || t.PkgPath() == "time"
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex allocating nil embedded pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldPtrs returns scan destinations for columns in order
+func fieldPtrs(dest reflect.Value, columns []string, fields structFields, mode ScanMode) ([]any, error) {
+	ptrs := make([]any, len(columns))
+	for i, col := range columns {
+		path, ok := fields[strings.ToLower(col)]
+		if !ok {
+			path, ok = fields[col]
+		}
+		if !ok {
+			if mode == ScanStrict {
+				return nil, fmt.Errorf("column %q has no matching field in %s", col, dest.Type())
+			}
+			ptrs[i] = new(any) // discard
+			continue
+		}
+		ptrs[i] = fieldByIndex(dest, path).Addr().Interface()
+	}
+	return ptrs, nil
+}
+
+// ScanStruct scans the current row of src into the struct dest points to, matching columns by `db` tags
+func ScanStruct[T any](src ColumnScanner, dest *T, mode ScanMode) error {
+	columns, err := src.Columns()
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(dest).Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("ScanStruct requires a struct, got %s", v.Type())
+	}
+	ptrs, err := fieldPtrs(v, columns, fieldsOf(v.Type()), mode)
+	if err != nil {
+		return err
+	}
+	return src.Scan(ptrs...)
+}
+
+// RowsToStructs is RowsToItems without the fieldPtrsFromItem callback; columns are matched by `db` tags.
+// Closes rows
+func RowsToStructs[T any](rows Rows, mode ScanMode) ([]T, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("RowsToStructs requires a struct type, got %s", t)
+	}
+	fields := fieldsOf(t)
+
+	var items []T
+	for rows.Next() {
+		var item T
+		ptrs, err := fieldPtrs(reflect.ValueOf(&item).Elem(), columns, fields, mode)
+		if err != nil {
+			return nil, err
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan failed. %w", err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during iterating rows. %w", err)
+	}
+	return items, nil
+}
+
+// QueryStructs runs query and scans all rows by `db` tags
+func QueryStructs[T any](ctx context.Context, q Queryer, mode ScanMode, query string, args ...any) ([]T, error) {
+	rows, err := q.QueryRows(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return RowsToStructs[T](rows, mode)
+}
+
+// QueryStruct runs query and scans the first row by `db` tags. ErrNoRows if there is none
+func QueryStruct[T any](ctx context.Context, q Queryer, mode ScanMode, query string, args ...any) (T, error) {
+	var item T
+	err := ScanStruct(q.QueryRow(ctx, query, args...), &item, mode)
+	return item, err
+}
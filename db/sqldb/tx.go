@@ -4,8 +4,52 @@ import "context"
 
 // Tx Transaction
 type Tx interface {
+	Queryer
+
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
-	Exec(ctx context.Context, query string, args ...any) (Result, error)
+
+	// Query is the same as QueryRows
 	Query(ctx context.Context, query string, args ...any) (Rows, error)
+
+	// Savepoint marks a point to roll back to without aborting the whole transaction. Can be nested
+	Savepoint(ctx context.Context, name string) error
+	// RollbackTo undoes everything after the savepoint; the savepoint stays valid
+	RollbackTo(ctx context.Context, name string) error
+	// Release forgets the savepoint, keeping its changes
+	Release(ctx context.Context, name string) error
+}
+
+type IsoLevel int
+
+const (
+	IsoLevelDefault IsoLevel = iota // DBMS default
+	IsoLevelReadUncommitted
+	IsoLevelReadCommitted
+	IsoLevelRepeatableRead
+	IsoLevelSerializable
+)
+
+func (l IsoLevel) String() string {
+	switch l {
+	case IsoLevelReadUncommitted:
+		return "READ UNCOMMITTED"
+	case IsoLevelReadCommitted:
+		return "READ COMMITTED"
+	case IsoLevelRepeatableRead:
+		return "REPEATABLE READ"
+	case IsoLevelSerializable:
+		return "SERIALIZABLE"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// TxOptions for Client.BeginTx. nil means DBMS defaults
+type TxOptions struct {
+	IsoLevel IsoLevel
+	ReadOnly bool
+	// Deferrable - PostgreSQL only; a SERIALIZABLE READ ONLY tx waits for a safe snapshot
+	// and then runs without serialization failures. Ignored by other DBMSs
+	Deferrable bool
 }
@@ -0,0 +1,95 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryClassifier is implemented by Clients that can tell transient transaction failures
+// (serialization failures, deadlocks, lock wait timeouts) from permanent ones
+type RetryClassifier interface {
+	IsRetryable(err error) bool
+}
+
+type WithTxOptions struct {
+	TxOptions   *TxOptions
+	MaxAttempts int           // total attempts including the first. default: 3
+	BaseBackoff time.Duration // default: 20ms. doubled per retry with full jitter
+	MaxBackoff  time.Duration // default: 1s
+}
+
+const (
+	defaultTxMaxAttempts = 3
+	defaultTxBaseBackoff = 20 * time.Millisecond
+	defaultTxMaxBackoff  = time.Second
+)
+
+// WithTx runs fn in a transaction. Commits if fn returns nil, rolls back on error or panic (re-panics).
+// On ErrDeadlock, ErrSerializationFailure or errors the client's RetryClassifier accepts,
+// the whole transaction is retried with jittered backoff, so fn must be safe to run more than once.
+// opts may be nil
+func WithTx(ctx context.Context, client Client, opts *WithTxOptions, fn func(tx Tx) error) error {
+	var o WithTxOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = defaultTxMaxAttempts
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = defaultTxBaseBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaultTxMaxBackoff
+	}
+	classifier, _ := client.(RetryClassifier)
+
+	backoff := o.BaseBackoff
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, client, o.TxOptions, fn)
+		if err == nil {
+			return nil
+		}
+		if !IsRetryable(err) && (classifier == nil || !classifier.IsRetryable(err)) {
+			return err
+		}
+		if attempt >= o.MaxAttempts {
+			return fmt.Errorf("transaction failed after %d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(rand.N(backoff + 1))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("transaction retry aborted: %w (last error: %v)", ctx.Err(), err)
+		}
+		backoff = min(backoff*2, o.MaxBackoff)
+	}
+}
+
+func runTx(ctx context.Context, client Client, txOpts *TxOptions, fn func(tx Tx) error) (err error) {
+	tx, err := client.BeginTx(ctx, txOpts)
+	if err != nil {
+		return err
+	}
+	// roll back even if ctx is already canceled
+	rollbackCtx := context.WithoutCancel(ctx)
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(rollbackCtx)
+			panic(p)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		if rbErr := tx.Rollback(rollbackCtx); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}